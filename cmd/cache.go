@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sample-crud/infra/cache"
+	"sample-crud/internal/config"
+	"sample-crud/internal/requestctx"
+	"sample-crud/internal/service"
+	"strconv"
+	"time"
+)
+
+const cacheUsage = `usage: cache <command> [argument]
+
+commands:
+  inspect <product id> [tenant]  show the cached value, TTL and schema version of a product
+  evict <product id> [tenant]    delete the cache entry of a product
+  evict-pattern <pattern>        delete every key matching pattern, e.g. sample_crud:default:product#1*
+  evict-tag <tag> [tenant]       delete every entry carrying tag, e.g. product:1 or list:all
+  flush                          delete every product entry, list page and tag of every tenant
+`
+
+func cacheAdmin(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		exitUsage(cacheUsage)
+	}
+	redisClient := cache.NewRedisClient(cfg.Redis)
+	defer cache.Close()
+	if !cache.Available() {
+		exitError(fmt.Errorf("redis is unavailable"))
+	}
+	cacheAdminService := service.NewCacheAdminService(redisClient)
+
+	ctx, cancel := context.WithTimeout(requestctx.WithTenant(context.Background(), cfg.Tenant.Default), time.Minute*5)
+	defer cancel()
+	var (
+		result interface{}
+		err    error
+	)
+	switch command := args[0]; command {
+	case "inspect", "evict":
+		if len(args) != 2 && len(args) != 3 {
+			exitUsage(cacheUsage)
+		}
+		if len(args) == 3 {
+			ctx = requestctx.WithTenant(ctx, args[2])
+		}
+		id, parseErr := strconv.ParseUint(args[1], 10, 64)
+		if parseErr != nil {
+			exitError(fmt.Errorf("invalid product id %q", args[1]))
+		}
+		if command == "inspect" {
+			result, err = cacheAdminService.InspectProduct(ctx, uint(id))
+		} else {
+			result, err = cacheAdminService.EvictProduct(ctx, uint(id))
+		}
+	case "evict-pattern":
+		if len(args) != 2 {
+			exitUsage(cacheUsage)
+		}
+		result, err = cacheAdminService.EvictPattern(ctx, args[1])
+	case "evict-tag":
+		if len(args) != 2 && len(args) != 3 {
+			exitUsage(cacheUsage)
+		}
+		if len(args) == 3 {
+			ctx = requestctx.WithTenant(ctx, args[2])
+		}
+		result, err = cacheAdminService.EvictTag(ctx, args[1])
+	case "flush":
+		result, err = cacheAdminService.FlushProductCache(ctx)
+	default:
+		exitUsage(cacheUsage)
+	}
+	if err != nil {
+		exitError(err)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(result)
+}
+
+func exitUsage(usage string) {
+	fmt.Fprint(os.Stderr, usage)
+	os.Exit(2)
+}
+
+func exitError(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(1)
+}
@@ -4,18 +4,25 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sample-crud/api"
 	"sample-crud/infra/cache"
 	"sample-crud/infra/db"
+	"sample-crud/infra/metrics"
 	"sample-crud/internal/config"
 	"sample-crud/internal/handler"
+	"sample-crud/internal/idempotency"
+	"sample-crud/internal/interceptor"
 	"sample-crud/internal/middleware"
+	"sample-crud/internal/outbox"
 	"sample-crud/internal/repo"
 	"sample-crud/internal/service"
+	"sample-crud/internal/tenant"
 	"sample-crud/proto/pb/product"
 	"strconv"
 	"syscall"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	commonhandler "github.com/tee-nullpointer/go-common-kit/handler"
 	commoninterceptor "github.com/tee-nullpointer/go-common-kit/interceptor"
 	commonmiddleware "github.com/tee-nullpointer/go-common-kit/middleware"
@@ -33,17 +40,54 @@ func main() {
 	}
 	defer logger.Sync()
 
-	gormDB := db.Init(cfg.Database)
+	command, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
+	}
+	switch command {
+	case "serve":
+		serve(cfg)
+	case "warmup":
+		warmup(cfg, args)
+	case "cache":
+		cacheAdmin(cfg, args)
+	case "migrate":
+		migrate(cfg, args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected one of: serve, warmup, cache, migrate\n", command)
+		os.Exit(2)
+	}
+}
+
+func serve(cfg *config.Config) {
+	dbResolver := db.Init(cfg.Database)
 	defer db.ShutDown()
+	if cfg.Database.AutoMigrate {
+		autoMigrate(dbResolver.Primary())
+	}
 
 	redisClient := cache.NewRedisClient(cfg.Redis)
 	defer cache.Close()
 
 	ginServer := server.NewGinServer(cfg.Server.Mode)
 	ginRouter := ginServer.GetRouter()
-	productRepo := repo.NewGormProductRepository(gormDB)
-	productService := service.NewProductService(productRepo, redisClient)
-	setupRouter(ginRouter, productService)
+	productRepo := newProductRepository(cfg.Database, dbResolver)
+	txManager := repo.NewGormTransactionManager(dbResolver, repo.ParseIsolationLevel(cfg.Database.TxIsolation), cfg.Database.TxMaxRetries)
+	outboxRepo := repo.NewGormOutboxRepository(dbResolver)
+	historyRepo := repo.NewGormProductHistoryRepository(dbResolver)
+	productService := service.NewProductService(productRepo, outboxRepo, historyRepo, txManager, redisClient)
+	if cfg.Outbox.Enabled {
+		relay := outbox.NewRelay(outboxRepo, txManager, newOutboxSink(cfg.Outbox, redisClient), cfg.Outbox)
+		relay.Start()
+		defer relay.Stop()
+	}
+	if cfg.Cache.WarmupEnabled {
+		warmupOnStartup(cfg.Cache, service.NewProductCacheWarmer(productRepo, redisClient))
+	}
+	tenantResolver := tenant.NewResolver(cfg.Tenant)
+	idempotencyStore := idempotency.NewStore(redisClient, cfg.Idempotency)
+	setupRouter(ginRouter, cfg.Server, productService, tenantResolver, idempotencyStore)
+	setupAdminRouter(ginRouter, cfg.Admin, cfg.Tenant, service.NewCacheAdminService(redisClient))
 	go ginServer.Start(cfg.Server.Host, cfg.Server.Port)
 
 	grpcServer := server.NewGRPCServer(
@@ -52,6 +96,9 @@ func main() {
 				commoninterceptor.RecoveryUnaryInterceptor,
 				commoninterceptor.TraceUnaryInterceptor,
 				commoninterceptor.LoggingUnaryInterceptor,
+				interceptor.DatabaseScopeUnaryInterceptor,
+				interceptor.ActorUnaryInterceptor,
+				interceptor.TenantUnaryInterceptor(tenantResolver),
 			),
 		),
 	)
@@ -65,21 +112,91 @@ func main() {
 	grpcServer.GracefulShutdown()
 }
 
-func setupRouter(router *gin.Engine, productService service.ProductService) {
+func newProductRepository(cfg config.DatabaseConfig, dbResolver *db.Resolver) repo.ProductRepository {
+	switch cfg.Repository {
+	case "", "gorm":
+		return repo.NewGormProductRepository(dbResolver)
+	case "pgx":
+		if cfg.Driver != db.DriverPostgres {
+			panic("The pgx product repository requires the postgres database driver")
+		}
+		return repo.NewPgxProductRepository(dbResolver)
+	default:
+		panic(fmt.Sprintf("Unsupported product repository %q", cfg.Repository))
+	}
+}
+
+func newOutboxSink(cfg config.OutboxConfig, redisClient redis.UniversalClient) outbox.Sink {
+	switch cfg.Sink {
+	case "redis":
+		return outbox.NewRedisStreamSink(redisClient, cfg.Stream, cfg.StreamMaxLen)
+	case "log":
+		return outbox.NewLogSink()
+	default:
+		panic(fmt.Sprintf("Unsupported outbox sink %q", cfg.Sink))
+	}
+}
+
+func setupRouter(router *gin.Engine, cfg config.ServerConfig, productService service.ProductService, tenantResolver *tenant.Resolver, idempotencyStore *idempotency.Store) {
+	doc, err := api.Load()
+	if err != nil {
+		panic(fmt.Sprintf("Fail to load OpenAPI specification: %v", err))
+	}
+	docsHandler, err := handler.NewDocsHandler(doc)
+	if err != nil {
+		panic(fmt.Sprintf("Fail to load OpenAPI specification: %v", err))
+	}
 	router.Use(gin.Recovery())
 	router.Use(commonmiddleware.TraceMiddleware())
 	router.Use(commonmiddleware.LoggingMiddleware())
+	if cfg.ValidateOpenAPI && cfg.Mode == gin.DebugMode {
+		validation, err := middleware.OpenAPIValidation(doc)
+		if err != nil {
+			panic(fmt.Sprintf("Fail to initialize OpenAPI validation: %v", err))
+		}
+		router.Use(validation)
+	}
 	router.Use(middleware.ErrorRecover())
+	router.Use(middleware.DatabaseScope())
+	router.Use(middleware.Actor())
 	productHandler := handler.NewProductApiHandler(productService)
 	monitor := router.Group("/")
 	{
 		monitor.GET("/health", commonhandler.HealthCheck)
+		monitor.GET("/metrics", gin.WrapH(metrics.Handler()))
+		monitor.GET("/openapi.json", docsHandler.OpenAPI)
+		monitor.GET("/docs/*filepath", docsHandler.UI)
 	}
-	v1 := router.Group("/api/v1")
+	v1 := router.Group("/api/v1", middleware.Tenant(tenantResolver), middleware.Idempotency(idempotencyStore))
 	{
 		v1.POST("/products", productHandler.Create)
+		v1.GET("/products", productHandler.List)
+		v1.GET("/products/export", productHandler.Export)
+		v1.POST("/products/import", productHandler.Import)
+		v1.GET("/products/import/reports/:report_id", productHandler.ImportReport)
 		v1.GET("/products/:id", productHandler.FindByID)
 		v1.PUT("/products/:id", productHandler.Update)
 		v1.DELETE("/products/:id", productHandler.Delete)
+		v1.GET("/products/:id/history", productHandler.History)
+		// :batchCreate, :batchUpsert and :batchDelete, see BatchAction.
+		v1.POST("/products:action", productHandler.BatchAction)
+	}
+}
+
+func setupAdminRouter(router *gin.Engine, cfg config.AdminConfig, tenantCfg config.TenantConfig, cacheAdminService service.CacheAdminService) {
+	if cfg.Token == "" {
+		zap.L().Info("ADMIN_TOKEN not set, admin endpoints disabled")
+		return
+	}
+	cacheAdminHandler := handler.NewCacheAdminApiHandler(cacheAdminService)
+	// The bearer token is the admin one, the tenant only comes from the header.
+	adminTenantResolver := tenant.NewResolver(config.TenantConfig{Required: tenantCfg.Required, Default: tenantCfg.Default})
+	admin := router.Group("/admin", middleware.AdminAuth(cfg.Token), middleware.Tenant(adminTenantResolver))
+	{
+		admin.GET("/cache/products/:id", cacheAdminHandler.InspectProduct)
+		admin.DELETE("/cache/products/:id", cacheAdminHandler.EvictProduct)
+		admin.DELETE("/cache/keys", cacheAdminHandler.EvictPattern)
+		admin.DELETE("/cache/tags/:tag", cacheAdminHandler.EvictTag)
+		admin.DELETE("/cache", cacheAdminHandler.FlushProductCache)
 	}
 }
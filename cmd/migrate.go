@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sample-crud/infra/db"
+	"sample-crud/infra/migration"
+	"sample-crud/internal/config"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  up                 apply every pending migration
+  down [-steps 1]    revert the last applied migrations
+  status             list migrations and whether they are applied
+  create <name>      create the next pair of up and down files
+                     [-dir infra/migration/migrations/<DATABASE_DRIVER>]
+`
+
+func migrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		exitUsage(migrateUsage)
+	}
+	command, args := args[0], args[1:]
+	if command == "create" {
+		flags := flag.NewFlagSet("migrate create", flag.ExitOnError)
+		dir := flags.String("dir", "infra/migration/migrations/"+cfg.Database.Driver, "directory of the migration files")
+		_ = flags.Parse(args)
+		if flags.NArg() != 1 {
+			exitUsage(migrateUsage)
+		}
+		paths, err := migration.Create(*dir, flags.Arg(0))
+		if err != nil {
+			exitError(err)
+		}
+		for _, path := range paths {
+			fmt.Println("created", path)
+		}
+		return
+	}
+
+	dbResolver := db.Init(cfg.Database)
+	defer db.ShutDown()
+	migrator := newMigrator(dbResolver.Primary())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			exitError(err)
+		}
+		fmt.Printf("applied %d migrations\n", applied)
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ExitOnError)
+		steps := flags.Int("steps", 1, "number of migrations to revert")
+		_ = flags.Parse(args)
+		reverted, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			exitError(err)
+		}
+		fmt.Printf("reverted %d migrations\n", reverted)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			exitError(err)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(statuses)
+	default:
+		exitUsage(migrateUsage)
+	}
+}
+
+func autoMigrate(gormDB *gorm.DB) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+	applied, err := newMigrator(gormDB).Up(ctx)
+	if err != nil {
+		panic(fmt.Sprintf("Fail to migrate database: %v", err))
+	}
+	zap.L().Info("Database migrated", zap.Int("applied", applied))
+}
+
+func newMigrator(gormDB *gorm.DB) *migration.Migrator {
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		exitError(err)
+	}
+	migrator, err := migration.NewMigrator(sqlDB, gormDB.Dialector.Name())
+	if err != nil {
+		exitError(err)
+	}
+	return migrator
+}
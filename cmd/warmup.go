@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sample-crud/infra/cache"
+	"sample-crud/infra/db"
+	"sample-crud/internal/config"
+	"sample-crud/internal/requestctx"
+	"sample-crud/internal/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func warmup(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("warmup", flag.ExitOnError)
+	size := flags.Int("n", cfg.Cache.WarmupSize, "number of most recently updated products to preload")
+	file := flags.String("file", cfg.Cache.WarmupFile, "file with one product id per line, preloaded instead of the most recent products")
+	timeout := flags.Duration("timeout", time.Minute*5, "maximum duration of the warmup")
+	_ = flags.Parse(args)
+
+	dbResolver := db.Init(cfg.Database)
+	defer db.ShutDown()
+	redisClient := cache.NewRedisClient(cfg.Redis)
+	defer cache.Close()
+	if !cache.Available() {
+		zap.L().Fatal("Redis is unavailable, nothing to warm up")
+	}
+
+	ctx, cancel := context.WithTimeout(requestctx.WithAllTenants(context.Background()), *timeout)
+	defer cancel()
+	warmer := service.NewProductCacheWarmer(newProductRepository(cfg.Database, dbResolver), redisClient)
+	if _, err := runWarmup(ctx, warmer, *size, *file); err != nil {
+		zap.L().Fatal("Cache warmup failed", zap.Error(err))
+	}
+}
+
+// warmupOnStartup never delays startup by more than the configured deadline,
+// whatever has not been preloaded by then is left to regular cache misses.
+func warmupOnStartup(cfg config.CacheConfig, warmer *service.ProductCacheWarmer) {
+	ctx, cancel := context.WithTimeout(requestctx.WithAllTenants(context.Background()), cfg.WarmupDeadline)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := runWarmup(ctx, warmer, cfg.WarmupSize, cfg.WarmupFile); err != nil {
+			zap.L().Warn("Cache warmup on startup failed", zap.Error(err))
+		}
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		zap.L().Warn("Cache warmup deadline exceeded, continuing startup", zap.Duration("deadline", cfg.WarmupDeadline))
+	}
+}
+
+func runWarmup(ctx context.Context, warmer *service.ProductCacheWarmer, size int, file string) (int, error) {
+	if file == "" {
+		return warmer.WarmRecent(ctx, size)
+	}
+	ids, err := readIDsFile(file)
+	if err != nil {
+		return 0, err
+	}
+	return warmer.WarmIDs(ctx, ids)
+}
+
+func readIDsFile(path string) ([]uint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ids []uint
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		id, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product id %q on line %d of %s", text, line, path)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, scanner.Err()
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+var ErrCacheUnavailable = errors.New("cache unavailable")
+
+type (
+	probeKey struct{}
+	bulkKey  struct{}
+)
+
+// Bulk marks ctx for bulk operations, such as admin scans and cache warmups,
+// whose commands are bounded by the deadline of ctx rather than the call
+// timeout and whose failures do not count toward opening the breaker. They
+// still fail fast while the breaker is open.
+func Bulk(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bulkKey{}, true)
+}
+
+func isBulk(ctx context.Context) bool {
+	return ctx.Value(bulkKey{}) != nil
+}
+
+// breaker is a go-redis hook that bounds every command outside of bulk
+// operations with a timeout and short-circuits all commands once too many
+// consecutive calls have failed. It is closed again only by a successful
+// background probe.
+type breaker struct {
+	mu        sync.RWMutex
+	open      bool
+	failures  int
+	threshold int
+	timeout   time.Duration
+}
+
+func newBreaker(threshold int, timeout time.Duration) *breaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &breaker{threshold: threshold, timeout: timeout}
+}
+
+func (b *breaker) isOpen() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.open
+}
+
+func (b *breaker) trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		zap.L().Warn("Redis circuit breaker opened, cache disabled")
+	}
+	b.open = true
+}
+
+func (b *breaker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open {
+		zap.L().Info("Redis circuit breaker closed, cache enabled")
+	}
+	b.open = false
+	b.failures = 0
+}
+
+// unavailableReplies are the error replies by which Redis reports that it
+// cannot serve commands, other error replies show it is answering. Among them
+// is the CLIENT SETINFO of the connection handshake, unknown before Redis 7.2.
+var unavailableReplies = []string{"LOADING", "BUSY", "MASTERDOWN", "CLUSTERDOWN", "TRYAGAIN"}
+
+func healthy(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return true
+	}
+	var reply redis.Error
+	if !errors.As(err, &reply) {
+		return false
+	}
+	for _, prefix := range unavailableReplies {
+		if strings.HasPrefix(reply.Error(), prefix+" ") {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *breaker) record(ctx context.Context, err error) {
+	if healthy(err) {
+		b.mu.Lock()
+		b.failures = 0
+		b.mu.Unlock()
+		return
+	}
+	// The caller giving up is not a sign that Redis is unhealthy, neither is
+	// a bulk operation outlasting its own deadline.
+	if isBulk(ctx) || (ctx.Err() != nil && errors.Is(ctx.Err(), context.Canceled)) {
+		return
+	}
+	b.mu.Lock()
+	b.failures++
+	shouldTrip := b.failures >= b.threshold
+	b.mu.Unlock()
+	if shouldTrip {
+		b.trip()
+	}
+}
+
+func (b *breaker) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.timeout <= 0 || isBulk(ctx) {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, b.timeout)
+}
+
+func (b *breaker) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (b *breaker) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if ctx.Value(probeKey{}) != nil {
+			return next(ctx, cmd)
+		}
+		if b.isOpen() {
+			cmd.SetErr(ErrCacheUnavailable)
+			return ErrCacheUnavailable
+		}
+		callCtx, cancel := b.withTimeout(ctx)
+		defer cancel()
+		err := next(callCtx, cmd)
+		b.record(ctx, err)
+		return err
+	}
+}
+
+func (b *breaker) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if ctx.Value(probeKey{}) != nil {
+			return next(ctx, cmds)
+		}
+		if b.isOpen() {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrCacheUnavailable)
+			}
+			return ErrCacheUnavailable
+		}
+		callCtx, cancel := b.withTimeout(ctx)
+		defer cancel()
+		err := next(callCtx, cmds)
+		b.record(ctx, err)
+		return err
+	}
+}
+
+func (b *breaker) probe(ctx context.Context, client redis.UniversalClient) error {
+	ctx, cancel := b.withTimeout(context.WithValue(ctx, probeKey{}, true))
+	defer cancel()
+	return client.Ping(ctx).Err()
+}
+
+func (b *breaker) reconnectLoop(client redis.UniversalClient, interval time.Duration, stop <-chan struct{}) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !b.isOpen() {
+				continue
+			}
+			if err := b.probe(context.Background(), client); err != nil {
+				zap.L().Debug("Redis still unavailable", zap.Error(err))
+				continue
+			}
+			b.reset()
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newBrokenClient returns a client with a breaker, and a function stopping
+// and restarting its Redis.
+func newBrokenClient(t *testing.T, threshold int) (*breaker, redis.UniversalClient, func(failing bool)) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	b := newBreaker(threshold, time.Second)
+	client.AddHook(b)
+	return b, client, func(failing bool) {
+		if failing {
+			server.Close()
+		} else if err := server.Restart(); err != nil {
+			t.Fatalf("restart Redis: %v", err)
+		}
+	}
+}
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	ctx := context.Background()
+	b, client, fail := newBrokenClient(t, 3)
+
+	fail(true)
+	_ = client.Get(ctx, "key").Err()
+	_ = client.Get(ctx, "key").Err()
+	fail(false)
+	_ = client.Get(ctx, "key").Err()
+	fail(true)
+	_ = client.Get(ctx, "key").Err()
+	_ = client.Get(ctx, "key").Err()
+	if b.isOpen() {
+		t.Fatal("expected a success to reset the failure count")
+	}
+	_ = client.Get(ctx, "key").Err()
+	if !b.isOpen() {
+		t.Fatal("expected the breaker open after 3 consecutive failures")
+	}
+
+	fail(false)
+	if err := client.Get(ctx, "key").Err(); !errors.Is(err, ErrCacheUnavailable) {
+		t.Fatalf("expected commands short-circuited while open, got %v", err)
+	}
+	if _, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "key")
+		return nil
+	}); !errors.Is(err, ErrCacheUnavailable) {
+		t.Fatalf("expected pipelines short-circuited while open, got %v", err)
+	}
+}
+
+func TestBreakerClosesOnceTheProbeSucceeds(t *testing.T) {
+	b, client, fail := newBrokenClient(t, 1)
+	fail(true)
+	_ = client.Get(context.Background(), "key").Err()
+	stop := make(chan struct{})
+	defer close(stop)
+	go b.reconnectLoop(client, 10*time.Millisecond, stop)
+
+	time.Sleep(50 * time.Millisecond)
+	if !b.isOpen() {
+		t.Fatal("expected the breaker to stay open while the probe fails")
+	}
+	fail(false)
+	deadline := time.Now().Add(time.Second)
+	for b.isOpen() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the breaker closed once the probe succeeds")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := client.Get(context.Background(), "key").Err(); !errors.Is(err, redis.Nil) {
+		t.Fatalf("expected commands to reach Redis again, got %v", err)
+	}
+}
+
+func TestErrorRepliesDoNotOpenTheBreaker(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	b := newBreaker(1, time.Second)
+	client.AddHook(b)
+
+	// miniredis, like Redis before 7.2, rejects the CLIENT SETINFO of the
+	// connection handshake.
+	_ = client.Get(context.Background(), "key").Err()
+	server.Set("key", "value")
+	_ = client.HGet(context.Background(), "key", "field").Err()
+	if b.isOpen() {
+		t.Fatal("expected error replies not to open the breaker")
+	}
+
+	server.SetError("LOADING Redis is loading the dataset in memory")
+	_ = client.Get(context.Background(), "key").Err()
+	if !b.isOpen() {
+		t.Fatal("expected the breaker open while Redis is loading")
+	}
+}
+
+func TestCanceledAndBulkCallsDoNotOpenTheBreaker(t *testing.T) {
+	b, client, fail := newBrokenClient(t, 1)
+	fail(true)
+
+	_ = client.Get(Bulk(context.Background()), "key").Err()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = client.Get(canceled, "key").Err()
+
+	if b.isOpen() {
+		t.Fatalf("expected canceled and bulk calls not to open the breaker, %d failures", b.failures)
+	}
+}
+
+func TestCallTimeoutDoesNotBoundBulkCalls(t *testing.T) {
+	b := newBreaker(1, 100*time.Millisecond)
+	var deadline time.Time
+	var bounded bool
+	process := b.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		deadline, bounded = ctx.Deadline()
+		return nil
+	})
+	ctx := context.Background()
+
+	_ = process(ctx, redis.NewCmd(ctx, "get", "key"))
+	if !bounded || time.Until(deadline) > 100*time.Millisecond {
+		t.Fatalf("expected a command bounded by the call timeout, got %v", deadline)
+	}
+	_ = process(Bulk(ctx), redis.NewCmd(ctx, "scan", 0))
+	if bounded {
+		t.Fatalf("expected a bulk command bounded only by its context, got %v", deadline)
+	}
+}
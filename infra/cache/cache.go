@@ -3,39 +3,140 @@ package cache
 import (
 	"context"
 	"fmt"
+	"sample-crud/infra/metrics"
 	"sample-crud/internal/config"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
-var client *redis.Client
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
 
-func NewRedisClient(cfg config.RedisConfig) *redis.Client {
-	client = redis.NewClient(&redis.Options{
-		Addr:            fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
-		Password:        cfg.Password,
-		DB:              cfg.DB,
-		PoolSize:        cfg.PoolSize,
-		ConnMaxIdleTime: cfg.IdleTime,
-		MinIdleConns:    cfg.MinIdle,
-		PoolTimeout:     cfg.MaxWait,
-	})
+var (
+	client redis.UniversalClient
+	cb     *breaker
+	stop   chan struct{}
+)
+
+// NewRedisClient never fails because Redis is unreachable: the client starts
+// with the cache disabled and reconnects in the background.
+func NewRedisClient(cfg config.RedisConfig) redis.UniversalClient {
+	var err error
+	client, err = newUniversalClient(cfg)
+	if err != nil {
+		panic(fmt.Errorf("failed to create Redis client: %w", err))
+	}
+	cb = newBreaker(cfg.BreakerThreshold, cfg.CallTimeout)
+	client.AddHook(cb)
+	metrics.RegisterRedisPool(client)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := client.Ping(ctx).Result()
-	if err != nil {
-		panic(fmt.Errorf("failed to connect to Redis: %w", err))
+	if err = cb.probe(ctx, client); err != nil {
+		zap.L().Warn("Fail to connect to Redis, starting with cache disabled", zap.Error(err))
+		cb.trip()
+	} else {
+		zap.L().Info("Successfully connected to Redis", zap.String("mode", cfg.Mode))
+		checkVersion(ctx, client)
 	}
 
-	zap.L().Info("Successfully connected to Redis")
+	stop = make(chan struct{})
+	go cb.reconnectLoop(client, cfg.ReconnectEvery, stop)
 	return client
 }
 
+// checkVersion reports a Redis older than 7, on which the tagged writes fail,
+// see tags.go.
+func checkVersion(ctx context.Context, client redis.UniversalClient) {
+	info, err := client.Info(ctx, "server").Result()
+	if err != nil {
+		zap.L().Warn("Fail to read the Redis version", zap.Error(err))
+		return
+	}
+	version := serverVersion(info)
+	if major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0]); err != nil || major < 7 {
+		zap.L().Error("Redis 7 or later is required by the tagged cache writes", zap.String("version", version))
+	}
+}
+
+// serverVersion reads redis_version from the server section of INFO.
+func serverVersion(info string) string {
+	for _, line := range strings.Split(info, "\n") {
+		if version, found := strings.CutPrefix(strings.TrimSpace(line), "redis_version:"); found {
+			return version
+		}
+	}
+	return ""
+}
+
+func Available() bool {
+	return cb != nil && !cb.isOpen()
+}
+
+func newUniversalClient(cfg config.RedisConfig) (redis.UniversalClient, error) {
+	addrs := cfg.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)}
+	}
+	switch cfg.Mode {
+	case "", ModeStandalone:
+		return redis.NewClient(&redis.Options{
+			Addr:            addrs[0],
+			Password:        cfg.Password,
+			DB:              cfg.DB,
+			PoolSize:        cfg.PoolSize,
+			ConnMaxIdleTime: cfg.IdleTime,
+			MinIdleConns:    cfg.MinIdle,
+			PoolTimeout:     cfg.MaxWait,
+
+			ContextTimeoutEnabled: true,
+		}), nil
+	case ModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, fmt.Errorf("redis sentinel mode requires a master name")
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    addrs,
+			SentinelPassword: cfg.SentinelPassword,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			PoolSize:         cfg.PoolSize,
+			ConnMaxIdleTime:  cfg.IdleTime,
+			MinIdleConns:     cfg.MinIdle,
+			PoolTimeout:      cfg.MaxWait,
+
+			ContextTimeoutEnabled: true,
+		}), nil
+	case ModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:           addrs,
+			Password:        cfg.Password,
+			PoolSize:        cfg.PoolSize,
+			ConnMaxIdleTime: cfg.IdleTime,
+			MinIdleConns:    cfg.MinIdle,
+			PoolTimeout:     cfg.MaxWait,
+
+			ContextTimeoutEnabled: true,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported redis mode %q", cfg.Mode)
+	}
+}
+
 func Close() {
+	if client == nil {
+		return
+	}
+	close(stop)
 	err := client.Close()
 	if err != nil {
 		return
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"net"
+	"sample-crud/internal/config"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestNewUniversalClientSelectsTheMode(t *testing.T) {
+	server := miniredis.RunT(t)
+	host, port, err := net.SplitHostPort(server.Addr())
+	if err != nil {
+		t.Fatalf("split address: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		cfg  config.RedisConfig
+		// check inspects the client, ping tells whether it must reach
+		// miniredis, which does not speak the sentinel protocol.
+		check func(t *testing.T, client redis.UniversalClient)
+		ping  bool
+	}{
+		{"default is standalone", config.RedisConfig{Host: host, Port: port}, func(t *testing.T, client redis.UniversalClient) {
+			if c, ok := client.(*redis.Client); !ok || c.Options().Addr != server.Addr() {
+				t.Fatalf("expected a standalone client of %s, got %T", server.Addr(), client)
+			}
+		}, true},
+		{"standalone prefers the first address", config.RedisConfig{Mode: ModeStandalone, Host: "unused", Port: "1", Addrs: []string{server.Addr(), "other:6379"}}, func(t *testing.T, client redis.UniversalClient) {
+			if c, ok := client.(*redis.Client); !ok || c.Options().Addr != server.Addr() {
+				t.Fatalf("expected a standalone client of %s, got %T", server.Addr(), client)
+			}
+		}, true},
+		{"sentinel", config.RedisConfig{Mode: ModeSentinel, MasterName: "mymaster", Addrs: []string{server.Addr()}}, func(t *testing.T, client redis.UniversalClient) {
+			if c, ok := client.(*redis.Client); !ok || c.Options().Addr != "FailoverClient" {
+				t.Fatalf("expected a failover client, got %T", client)
+			}
+		}, false},
+		{"cluster", config.RedisConfig{Mode: ModeCluster, Addrs: []string{server.Addr()}}, func(t *testing.T, client redis.UniversalClient) {
+			if _, ok := client.(*redis.ClusterClient); !ok {
+				t.Fatalf("expected a cluster client, got %T", client)
+			}
+		}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := newUniversalClient(tc.cfg)
+			if err != nil {
+				t.Fatalf("newUniversalClient: %v", err)
+			}
+			t.Cleanup(func() { _ = client.Close() })
+			tc.check(t, client)
+			if tc.ping {
+				if err := client.Ping(context.Background()).Err(); err != nil {
+					t.Fatalf("ping: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestNewUniversalClientRejectsInvalidConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     config.RedisConfig
+		message string
+	}{
+		{"sentinel without master", config.RedisConfig{Mode: ModeSentinel, Addrs: []string{"localhost:26379"}}, "master name"},
+		{"unknown mode", config.RedisConfig{Mode: "replicated", Host: "localhost", Port: "6379"}, `unsupported redis mode "replicated"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := newUniversalClient(tc.cfg)
+			if err == nil {
+				_ = client.Close()
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.message) {
+				t.Fatalf("expected an error about %s, got %v", tc.message, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	Namespace = "sample_crud"
+	scanCount = 500
+)
+
+// DeleteKeys deletes keys one by one in a single pipeline because a multi-key
+// DEL spanning several hash slots is rejected in cluster mode. It returns the
+// number of keys that existed.
+func DeleteKeys(ctx context.Context, client redis.UniversalClient, keys []string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, cmd := range cmds {
+		deleted += cmd.(*redis.IntCmd).Val()
+	}
+	return deleted, nil
+}
+
+// ScanKeys walks every key matching pattern with SCAN, on every master when
+// running against a cluster, and hands them to fn one page at a time. The walk
+// is a bulk operation, see Bulk.
+func ScanKeys(ctx context.Context, client redis.UniversalClient, pattern string, fn func(keys []string) error) error {
+	ctx = Bulk(ctx)
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scanNode(ctx, node, pattern, fn)
+		})
+	}
+	return scanNode(ctx, client, pattern, fn)
+}
+
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// DeleteMatching deletes every key matching pattern and returns how many were
+// deleted, as a bulk operation.
+func DeleteMatching(ctx context.Context, client redis.UniversalClient, pattern string) (int64, error) {
+	ctx = Bulk(ctx)
+	var deleted int64
+	err := ScanKeys(ctx, client, pattern, func(keys []string) error {
+		n, err := DeleteKeys(ctx, client, keys)
+		deleted += n
+		return err
+	})
+	return deleted, err
+}
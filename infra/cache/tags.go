@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Tag based invalidation keeps, for every tag, a Redis set with the keys of
+// the entries carrying it. The consistency rules are:
+//   - an entry is registered in all of its tag sets in the same round trip
+//     it is written, so a tagged entry is never visible without its tags;
+//   - a tag set never expires before the entries it lists, its TTL is only
+//     ever extended;
+//   - invalidating a tag atomically reads and drops its set before deleting
+//     the listed entries, so entries tagged concurrently end up in a new set
+//     instead of being lost;
+//   - invalidating a tag first bumps its version, and a read-through that read
+//     the versions before loading its data drops what it cached when one
+//     moved meanwhile, see DropIfInvalidated. Until it does, for one round
+//     trip, the stale entry is visible. Pipelined bulk writes, like the
+//     warmup, are not checked.
+//
+// The TTL rules use EXPIRE NX and GT, which need Redis 7.
+const (
+	tagKeyPrefix        = "sample_crud:tag#"
+	tagVersionKeyPrefix = "sample_crud:tag_version#"
+	// tagVersionTTL only has to outlive the loads of the read-throughs.
+	tagVersionTTL = time.Hour
+)
+
+func TagKey(tag string) string {
+	return tagKeyPrefix + tag
+}
+
+func tagVersionKey(tag string) string {
+	return tagVersionKeyPrefix + tag
+}
+
+// TagVersions holds the versions of tags, empty for tags never invalidated.
+type TagVersions map[string]string
+
+// ReadTagVersions is called by a read-through before it loads the data it
+// caches, with the tags whose invalidation makes that data stale.
+func ReadTagVersions(ctx context.Context, client redis.UniversalClient, tags ...string) (TagVersions, error) {
+	cmds := make(map[string]*redis.StringCmd, len(tags))
+	// One GET per tag, a multi-key MGET spanning several hash slots being
+	// rejected in cluster mode.
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, tag := range tags {
+			cmds[tag] = pipe.Get(ctx, tagVersionKey(tag))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	versions := make(TagVersions, len(tags))
+	for tag, cmd := range cmds {
+		if err := cmd.Err(); err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+		versions[tag] = cmd.Val()
+	}
+	return versions, nil
+}
+
+// DropIfInvalidated is called by a read-through once it has cached keys: it
+// deletes them again when one of the tags was invalidated since versions were
+// read, the data loaded in between being possibly stale. It tells whether the
+// keys were kept.
+func DropIfInvalidated(ctx context.Context, client redis.UniversalClient, versions TagVersions, keys ...string) (bool, error) {
+	tags := make([]string, 0, len(versions))
+	for tag := range versions {
+		tags = append(tags, tag)
+	}
+	current, err := ReadTagVersions(ctx, client, tags...)
+	if err != nil {
+		return false, err
+	}
+	for tag, version := range versions {
+		if current[tag] != version {
+			_, err := DeleteKeys(ctx, client, keys)
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func SetWithTags(ctx context.Context, client redis.UniversalClient, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		PipeSetWithTags(ctx, pipe, key, value, ttl, tags...)
+		return nil
+	})
+	return err
+}
+
+// PipeSetWithTags queues a tagged write on an existing pipeline, for callers
+// writing many entries in a single round trip.
+func PipeSetWithTags(ctx context.Context, pipe redis.Pipeliner, key string, value interface{}, ttl time.Duration, tags ...string) {
+	pipe.Set(ctx, key, value, ttl)
+	for _, tag := range tags {
+		tagKey := TagKey(tag)
+		pipe.SAdd(ctx, tagKey, key)
+		pipe.ExpireNX(ctx, tagKey, ttl)
+		pipe.ExpireGT(ctx, tagKey, ttl)
+	}
+}
+
+// InvalidateTags returns the number of entries it deleted.
+func InvalidateTags(ctx context.Context, client redis.UniversalClient, tags ...string) (int64, error) {
+	var keys []string
+	// The versions move before the sets are read, so that a read-through
+	// caching after the sets are read sees the new versions.
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, tag := range tags {
+			pipe.Incr(ctx, tagVersionKey(tag))
+			pipe.Expire(ctx, tagVersionKey(tag), tagVersionTTL)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, tag := range tags {
+		tagKey := TagKey(tag)
+		var members *redis.StringSliceCmd
+		_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			members = pipe.SMembers(ctx, tagKey)
+			pipe.Del(ctx, tagKey)
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		keys = append(keys, members.Val()...)
+	}
+	return DeleteKeys(ctx, client, keys)
+}
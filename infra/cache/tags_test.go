@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newMiniredisClient(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return server, client
+}
+
+func TestSetWithTagsRegistersTheEntryInItsTags(t *testing.T) {
+	server, client := newMiniredisClient(t)
+	ctx := context.Background()
+
+	if err := SetWithTags(ctx, client, "entry", "value", time.Minute, "a", "b"); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+
+	for _, tag := range []string{"a", "b"} {
+		if members, err := server.Members(TagKey(tag)); err != nil || len(members) != 1 || members[0] != "entry" {
+			t.Fatalf("expected tag %s to list the entry, got %v %v", tag, members, err)
+		}
+		if ttl := server.TTL(TagKey(tag)); ttl != time.Minute {
+			t.Fatalf("expected tag %s to expire with the entry, got %s", tag, ttl)
+		}
+	}
+}
+
+func TestTagTTLIsOnlyExtended(t *testing.T) {
+	server, client := newMiniredisClient(t)
+	ctx := context.Background()
+
+	if err := SetWithTags(ctx, client, "long", "value", time.Hour, "a"); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if err := SetWithTags(ctx, client, "short", "value", time.Minute, "a"); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if ttl := server.TTL(TagKey("a")); ttl != time.Hour {
+		t.Fatalf("expected the tag to outlive its longest entry, got %s", ttl)
+	}
+	if err := SetWithTags(ctx, client, "longer", "value", 2*time.Hour, "a"); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if ttl := server.TTL(TagKey("a")); ttl != 2*time.Hour {
+		t.Fatalf("expected the tag TTL extended, got %s", ttl)
+	}
+}
+
+func TestInvalidateTagsDeletesTheTaggedEntries(t *testing.T) {
+	server, client := newMiniredisClient(t)
+	ctx := context.Background()
+	for _, entry := range []struct{ key, tag string }{{"one", "a"}, {"two", "a"}, {"three", "b"}} {
+		if err := SetWithTags(ctx, client, entry.key, "value", time.Minute, entry.tag); err != nil {
+			t.Fatalf("SetWithTags: %v", err)
+		}
+	}
+
+	deleted, err := InvalidateTags(ctx, client, "a")
+	if err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+
+	if deleted != 2 || server.Exists("one") || server.Exists("two") || server.Exists(TagKey("a")) {
+		t.Fatalf("expected the entries of tag a and its set deleted, deleted %d, keys %v", deleted, server.Keys())
+	}
+	if !server.Exists("three") {
+		t.Fatal("expected the entries of other tags kept")
+	}
+
+	// An entry tagged after the invalidation lands in a new set.
+	if err := SetWithTags(ctx, client, "four", "value", time.Minute, "a"); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	if members, _ := server.Members(TagKey("a")); len(members) != 1 || members[0] != "four" {
+		t.Fatalf("expected a new set for tag a, got %v", members)
+	}
+}
+
+func TestReadThroughKeepsItsEntryWithoutInvalidation(t *testing.T) {
+	server, client := newMiniredisClient(t)
+	ctx := context.Background()
+	if _, err := InvalidateTags(ctx, client, "a"); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+
+	versions, err := ReadTagVersions(ctx, client, "a", "b")
+	if err != nil {
+		t.Fatalf("ReadTagVersions: %v", err)
+	}
+	if err := SetWithTags(ctx, client, "entry", "fresh", time.Minute, "a", "b"); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	kept, err := DropIfInvalidated(ctx, client, versions, "entry")
+	if err != nil {
+		t.Fatalf("DropIfInvalidated: %v", err)
+	}
+	if !kept || !server.Exists("entry") {
+		t.Fatal("expected the entry kept")
+	}
+}
+
+// A read-through loading its data before an invalidation and caching it after
+// must not leave the stale data cached.
+func TestReadThroughDropsDataLoadedBeforeAnInvalidation(t *testing.T) {
+	server, client := newMiniredisClient(t)
+	ctx := context.Background()
+
+	versions, err := ReadTagVersions(ctx, client, "a")
+	if err != nil {
+		t.Fatalf("ReadTagVersions: %v", err)
+	}
+	// The data is loaded here, then a writer changes it and invalidates.
+	if _, err := InvalidateTags(ctx, client, "a"); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	if err := SetWithTags(ctx, client, "entry", "stale", time.Minute, "a"); err != nil {
+		t.Fatalf("SetWithTags: %v", err)
+	}
+	kept, err := DropIfInvalidated(ctx, client, versions, "entry")
+	if err != nil {
+		t.Fatalf("DropIfInvalidated: %v", err)
+	}
+
+	if kept || server.Exists("entry") {
+		t.Fatal("expected the stale entry dropped")
+	}
+}
+
+func TestServerVersion(t *testing.T) {
+	info := "# Server\r\nredis_version:7.2.4\r\nredis_mode:standalone\r\n"
+	if version := serverVersion(info); version != "7.2.4" {
+		t.Fatalf("expected 7.2.4, got %q", version)
+	}
+	if version := serverVersion("# Clients\r\nconnected_clients:1\r\n"); version != "" {
+		t.Fatalf("expected no version, got %q", version)
+	}
+}
@@ -3,47 +3,164 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"net"
+	"sample-crud/infra/metrics"
 	"sample-crud/internal/config"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+
+	// Schema holds the tables on Postgres, SQLite has no schemas.
+	Schema = "sample"
+
+	// repoPackage is where the query latency metrics look for the
+	// repository method that issued a statement.
+	repoPackage = "sample-crud/internal/repo"
 )
 
 var (
-	db    *gorm.DB
-	sqlDB *sql.DB
+	db       *gorm.DB
+	sqlDB    *sql.DB
+	resolver *Resolver
 )
 
-func Init(config config.DatabaseConfig) *gorm.DB {
-	zap.L().Info("Initializing database connection")
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-		config.Host, config.User, config.Password, config.Name, config.Port, config.SSLMode)
+func Init(config config.DatabaseConfig) *Resolver {
+	zap.L().Info("Initializing database connection", zap.String("driver", config.Driver))
 	var dbErr error
-	db, dbErr = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, sqlDB, dbErr = openWithRetry(config, config.Host, config.Port)
 	if dbErr != nil {
 		panic(fmt.Sprintf("Fail to initialize database connection: %v", dbErr))
 	}
-	var sqlDBErr error
-	sqlDB, sqlDBErr = db.DB()
-	if sqlDBErr != nil {
-		panic(fmt.Sprintf("Fail to initialize database connection: %v", sqlDBErr))
-	}
 	zap.L().Info("Database connection established")
+	metrics.RegisterDBStats("primary", sqlDB)
 	zap.L().Info("Setting database connection pool parameters",
 		zap.Int("Max Connection", config.MaxConnection),
 		zap.Int("Max Idle", config.MaxIdle),
 		zap.Duration("Max Lifetime", config.MaxLifetime),
 		zap.Duration("Max Idle Time", config.MaxIdleTime))
-	sqlDB.SetMaxOpenConns(config.MaxConnection)
-	sqlDB.SetMaxIdleConns(config.MaxIdle)
-	sqlDB.SetConnMaxLifetime(config.MaxLifetime)
-	sqlDB.SetConnMaxIdleTime(config.MaxIdleTime)
-	return db
+
+	replicas := make([]*replica, 0, len(config.ReplicaHosts))
+	if config.Driver == DriverSQLite && len(config.ReplicaHosts) > 0 {
+		zap.L().Warn("Read replicas are not supported with SQLite, ignoring them")
+		config.ReplicaHosts = nil
+	}
+	for _, address := range config.ReplicaHosts {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			host, port = address, config.Port
+		}
+		zap.L().Info("Initializing read replica connection", zap.String("replica", address))
+		replicaDB, replicaSQLDB, err := openWithRetry(config, host, port)
+		if err != nil {
+			panic(fmt.Sprintf("Fail to initialize read replica connection %s: %v", address, err))
+		}
+		metrics.RegisterDBStats(address, replicaSQLDB)
+		replicas = append(replicas, &replica{name: address, db: replicaDB, sqlDB: replicaSQLDB})
+	}
+	resolver = newResolver(db, replicas, config)
+	return resolver
+}
+
+// Open connects to the primary alone, apart from the connection Init keeps for
+// the process, for tests that need a database of their own.
+func Open(config config.DatabaseConfig) (*Resolver, error) {
+	gormDB, _, err := open(config, config.Host, config.Port)
+	if err != nil {
+		return nil, err
+	}
+	return newResolver(gormDB, nil, config), nil
+}
+
+// openWithRetry keeps trying to connect with an exponential backoff, so that
+// an instance started while the database restarts waits for it instead of
+// crashing.
+func openWithRetry(config config.DatabaseConfig, host string, port string) (*gorm.DB, *sql.DB, error) {
+	backoff := config.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		gormDB, pool, err := open(config, host, port)
+		if err == nil || attempt > config.ConnectRetries {
+			return gormDB, pool, err
+		}
+		zap.L().Warn("Fail to connect to database, retrying",
+			zap.String("host", host), zap.Int("attempt", attempt), zap.Int("max retries", config.ConnectRetries),
+			zap.Duration("backoff", backoff), zap.Error(err))
+		time.Sleep(backoff)
+		backoff = min(backoff*2, config.ConnectMaxBackoff)
+	}
+}
+
+func open(config config.DatabaseConfig, host string, port string) (*gorm.DB, *sql.DB, error) {
+	var dialector gorm.Dialector
+	switch config.Driver {
+	case DriverPostgres:
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+			host, config.User, config.Password, config.Name, port, config.SSLMode)
+		// The longest operation timeout is the server side default, a
+		// backstop for statements whose client stopped waiting.
+		if config.BulkTimeout > 0 {
+			dsn += fmt.Sprintf(" statement_timeout=%d", config.BulkTimeout.Milliseconds())
+		}
+		dialector = postgres.Open(dsn)
+	case DriverSQLite:
+		dialector = sqlite.Open(config.DSN)
+	default:
+		return nil, nil, fmt.Errorf("unsupported database driver %q", config.Driver)
+	}
+	gormDB, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         newGormLogger(config.SlowQueryThreshold),
+		NamingStrategy: namingStrategy(config.Driver),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := gormDB.Use(tenantScope{}); err != nil {
+		return nil, nil, err
+	}
+	if err := gormDB.Use(metrics.NewGormPlugin(repoPackage)); err != nil {
+		return nil, nil, err
+	}
+	pool, err := gormDB.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	if config.Driver == DriverSQLite {
+		// SQLite has a single writer, and an in-memory database only lives as
+		// long as its connection, so keep exactly one open forever.
+		pool.SetMaxOpenConns(1)
+		pool.SetMaxIdleConns(1)
+		return gormDB, pool, nil
+	}
+	pool.SetMaxOpenConns(config.MaxConnection)
+	pool.SetMaxIdleConns(config.MaxIdle)
+	pool.SetConnMaxLifetime(config.MaxLifetime)
+	pool.SetConnMaxIdleTime(config.MaxIdleTime)
+	return gormDB, pool, nil
+}
+
+// namingStrategy qualifies the tables with Schema on Postgres. It belongs to
+// the connection, so that connections to both databases can live in the same
+// process. The models give their table names, see domain.Product.
+func namingStrategy(driver string) schema.NamingStrategy {
+	if driver == DriverPostgres {
+		return schema.NamingStrategy{TablePrefix: Schema + ".", SingularTable: true}
+	}
+	return schema.NamingStrategy{SingularTable: true}
 }
 
 func ShutDown() {
 	zap.L().Info("Shutting down database connection")
+	if resolver != nil {
+		resolver.close()
+	}
 	if sqlDB != nil {
 		if err := sqlDB.Close(); err != nil {
 			zap.L().Error("Fail to close database connection", zap.Error(err))
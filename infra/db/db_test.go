@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"sample-crud/internal/config"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/requestctx"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// The table schema belongs to each connection, opening SQLite first, as the
+// repository tests do, must not change the tables Postgres queries.
+func TestSQLiteAndPostgresConnectionsInTheSameProcess(t *testing.T) {
+	ctx := requestctx.WithAllTenants(context.Background())
+	sqlite, err := Open(config.DatabaseConfig{Driver: DriverSQLite, DSN: "file::memory:"})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqliteQuery := sqlite.Primary().Session(&gorm.Session{DryRun: true}).WithContext(ctx).Find(&[]domain.Product{}).Statement.SQL.String()
+
+	pg, err := gorm.Open(postgres.Open("host=127.0.0.1 port=1"), &gorm.Config{
+		NamingStrategy:       namingStrategy(DriverPostgres),
+		DisableAutomaticPing: true,
+		DryRun:               true,
+	})
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	postgresQuery := pg.WithContext(ctx).Find(&[]domain.Product{}).Statement.SQL.String()
+
+	if strings.Contains(sqliteQuery, "sample") || !strings.Contains(sqliteQuery, "`products`") {
+		t.Fatalf("expected SQLite to query an unqualified products table, got %q", sqliteQuery)
+	}
+	if !strings.Contains(postgresQuery, `"sample"."products"`) {
+		t.Fatalf("expected Postgres to query sample.products, got %q", postgresQuery)
+	}
+}
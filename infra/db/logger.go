@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger writes GORM logs through the logger of the context, so they carry
+// its trace id. Every statement is logged at debug level, those slower than
+// slowThreshold as warnings and failed ones as errors, always without their
+// bound parameters.
+type gormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+func (l gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	l.level = level
+	return l
+}
+
+func (l gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logger.GetLogger(ctx).SInfo(msg, data...)
+	}
+}
+
+func (l gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logger.GetLogger(ctx).SWarn(msg, data...)
+	}
+}
+
+func (l gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logger.GetLogger(ctx).SError(msg, data...)
+	}
+}
+
+func (l gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	fields := func() []zap.Field {
+		sql, rows := fc()
+		return []zap.Field{zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed)}
+	}
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		logger.GetLogger(ctx).Error("Database query failed", append(fields(), zap.Error(err))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		logger.GetLogger(ctx).Warn("Slow database query", append(fields(), zap.Duration("threshold", l.slowThreshold))...)
+	case l.level >= gormlogger.Info:
+		logger.GetLogger(ctx).Debug("Database query", fields()...)
+	}
+}
+
+// ParamsFilter keeps bound parameters, which may hold personal data, out of
+// the logged statements.
+func (l gormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// newGormLogger follows LOG_LEVEL through the level of the global zap logger:
+// debug logs every statement, info and warn the slow ones, error only failures.
+func newGormLogger(slowThreshold time.Duration) gormLogger {
+	core := zap.L().Core()
+	level := gormlogger.Error
+	switch {
+	case core.Enabled(zapcore.DebugLevel):
+		level = gormlogger.Info
+	case core.Enabled(zapcore.WarnLevel):
+		level = gormlogger.Warn
+	}
+	return gormLogger{level: level, slowThreshold: slowThreshold}
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sample-crud/internal/config"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const replicaPingTimeout = 2 * time.Second
+
+type (
+	primaryKey struct{}
+	scopeKey   struct{}
+)
+
+// Resolver routes read-only queries to healthy read replicas in round robin
+// and everything else to the primary. Once a request scope has written, its
+// reads are pinned to the primary so that it always reads its own writes.
+type Resolver struct {
+	primary     *gorm.DB
+	replicas    []*replica
+	next        atomic.Uint64
+	stop        chan struct{}
+	wg          sync.WaitGroup
+	budget      *retryBudget
+	maxAttempts int
+	timeouts    map[Operation]time.Duration
+}
+
+type replica struct {
+	name    string
+	db      *gorm.DB
+	sqlDB   *sql.DB
+	healthy atomic.Bool
+}
+
+func newResolver(primary *gorm.DB, replicas []*replica, config config.DatabaseConfig) *Resolver {
+	r := &Resolver{
+		primary:     primary,
+		replicas:    replicas,
+		stop:        make(chan struct{}),
+		budget:      newRetryBudget(config.RetryBudget),
+		maxAttempts: config.RetryMaxAttempts,
+		timeouts: map[Operation]time.Duration{
+			OperationRead:  config.ReadTimeout,
+			OperationWrite: config.WriteTimeout,
+			OperationBulk:  config.BulkTimeout,
+		},
+	}
+	if len(replicas) > 0 {
+		r.checkReplicas()
+		r.wg.Add(1)
+		go r.healthLoop(config.ReplicaHealthEvery)
+	}
+	return r
+}
+
+func (r *Resolver) Primary() *gorm.DB {
+	return r.primary
+}
+
+// Reader returns a connection for a read-only query.
+func (r *Resolver) Reader(ctx context.Context) *gorm.DB {
+	if len(r.replicas) == 0 || usePrimary(ctx) {
+		return r.primary
+	}
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		candidate := r.replicas[(int(start)+i)%len(r.replicas)]
+		if candidate.healthy.Load() {
+			return candidate.db
+		}
+	}
+	return r.primary
+}
+
+// Writer returns the primary and pins the rest of the request scope to it.
+func (r *Resolver) Writer(ctx context.Context) *gorm.DB {
+	MarkWritten(ctx)
+	return r.primary
+}
+
+// WithRequestScope starts a scope, usually one per request, in which reads
+// following a write go to the primary.
+func WithRequestScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, new(atomic.Bool))
+}
+
+// WithPrimary forces every read made with ctx to go to the primary.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func MarkWritten(ctx context.Context) {
+	if written, ok := ctx.Value(scopeKey{}).(*atomic.Bool); ok {
+		written.Store(true)
+	}
+}
+
+func usePrimary(ctx context.Context) bool {
+	if forced, _ := ctx.Value(primaryKey{}).(bool); forced {
+		return true
+	}
+	written, ok := ctx.Value(scopeKey{}).(*atomic.Bool)
+	return ok && written.Load()
+}
+
+func (r *Resolver) healthLoop(interval time.Duration) {
+	defer r.wg.Done()
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.checkReplicas()
+		}
+	}
+}
+
+func (r *Resolver) checkReplicas() {
+	for _, rep := range r.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+		err := rep.sqlDB.PingContext(ctx)
+		cancel()
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) != healthy {
+			if healthy {
+				zap.L().Info("Read replica is healthy", zap.String("replica", rep.name))
+			} else {
+				zap.L().Warn("Read replica is unhealthy, reads fall back to other replicas or the primary", zap.String("replica", rep.name), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (r *Resolver) close() {
+	close(r.stop)
+	r.wg.Wait()
+	for _, rep := range r.replicas {
+		if err := rep.sqlDB.Close(); err != nil {
+			zap.L().Error("Fail to close read replica connection", zap.String("replica", rep.name), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+type ErrorClass int
+
+const (
+	ErrorPermanent ErrorClass = iota
+	ErrorSerialization
+	ErrorDeadlock
+	ErrorConnection
+	ErrorTimeout
+)
+
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgConnectionException  = "08"
+	pgAdminShutdown        = "57P01"
+	pgCrashShutdown        = "57P02"
+	pgCannotConnectNow     = "57P03"
+	pgQueryCanceled        = "57014"
+
+	// retryBudgetRatio is the share of a retry token earned back by every
+	// success, so that retries stay under about a tenth of the calls.
+	retryBudgetRatio = 0.1
+	retryBaseBackoff = time.Millisecond * 20
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorSerialization:
+		return "serialization"
+	case ErrorDeadlock:
+		return "deadlock"
+	case ErrorConnection:
+		return "connection"
+	case ErrorTimeout:
+		return "timeout"
+	default:
+		return "permanent"
+	}
+}
+
+// Classify tells the transient errors worth retrying apart from the others.
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ErrorPermanent
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == pgSerializationFailure:
+			return ErrorSerialization
+		case pgErr.Code == pgDeadlockDetected:
+			return ErrorDeadlock
+		case pgErr.Code == pgQueryCanceled:
+			return ErrorTimeout
+		case strings.HasPrefix(pgErr.Code, pgConnectionException),
+			pgErr.Code == pgAdminShutdown, pgErr.Code == pgCrashShutdown, pgErr.Code == pgCannotConnectNow:
+			return ErrorConnection
+		default:
+			return ErrorPermanent
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+	// The caller giving up is not a database failure.
+	if errors.Is(err, context.Canceled) {
+		return ErrorPermanent
+	}
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) ||
+		errors.As(err, &netErr) || pgconn.SafeToRetry(err) {
+		return ErrorConnection
+	}
+	return ErrorPermanent
+}
+
+// IsTransient leaves timeouts out, running a statement that was too slow
+// again would most likely be too slow again.
+func IsTransient(err error) bool {
+	class := Classify(err)
+	return class == ErrorSerialization || class == ErrorDeadlock || class == ErrorConnection
+}
+
+// retryBudget is a token bucket in the spirit of gRPC retry throttling: every
+// transient failure costs a token, every success earns a fraction back, and
+// retries stop while fewer than half of the tokens are left. It keeps retries
+// from piling load on a database that is already failing.
+type retryBudget struct {
+	mu     sync.Mutex
+	tokens float64
+	max    float64
+}
+
+func newRetryBudget(tokens int) *retryBudget {
+	return &retryBudget{tokens: float64(tokens), max: float64(tokens)}
+}
+
+func (b *retryBudget) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+retryBudgetRatio, b.max)
+}
+
+// onFailure reports whether a retry is allowed.
+func (b *retryBudget) onFailure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = max(b.tokens-1, 0)
+	return b.tokens > b.max/2
+}
+
+// Retry runs fn again after a transient error, up to the configured attempts
+// and while the retry budget lasts. fn must be idempotent and must not run in
+// a transaction, which a failure aborts as a whole.
+func (r *Resolver) Retry(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			r.budget.onSuccess()
+			return nil
+		}
+		if !IsTransient(err) {
+			return err
+		}
+		class := Classify(err)
+		if !r.budget.onFailure() || attempt >= r.maxAttempts {
+			return err
+		}
+		backoff := retryBaseBackoff << (attempt - 1)
+		backoff += rand.N(backoff)
+		zap.L().Warn("Transient database error, retrying",
+			zap.Stringer("class", class), zap.Int("attempt", attempt), zap.Duration("backoff", backoff), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
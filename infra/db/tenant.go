@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sample-crud/internal/requestctx"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const tenantField = "TenantID"
+
+var (
+	ErrTenantRequired = errors.New("tenant required")
+	ErrTenantMismatch = errors.New("record belongs to another tenant")
+)
+
+// TenantFilter returns the tenant the queries made with ctx are scoped to, or
+// an empty tenant when ctx is explicitly scoped to all tenants.
+func TenantFilter(ctx context.Context) (string, error) {
+	if requestctx.AllTenants(ctx) {
+		return "", nil
+	}
+	if tenant := requestctx.Tenant(ctx); tenant != "" {
+		return tenant, nil
+	}
+	return "", ErrTenantRequired
+}
+
+// AssignTenant returns the tenant a new record owned by current must be
+// written with: the tenant of ctx, or current itself across tenants.
+func AssignTenant(ctx context.Context, current string) (string, error) {
+	tenant, err := TenantFilter(ctx)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case tenant == "" && current == "":
+		return "", ErrTenantRequired
+	case tenant == "":
+		return current, nil
+	case current != "" && current != tenant:
+		return "", ErrTenantMismatch
+	default:
+		return tenant, nil
+	}
+}
+
+// tenantScope is a GORM plugin scoping every query, update and delete of a
+// model with a TenantID field to the tenant of the statement context, and
+// stamping created records with it. A statement without a tenant fails, so a
+// repository cannot forget the scope. Raw SQL is not covered.
+type tenantScope struct{}
+
+func (tenantScope) Name() string {
+	return "tenant_scope"
+}
+
+func (tenantScope) Initialize(gormDB *gorm.DB) error {
+	callback := gormDB.Callback()
+	if err := callback.Create().Before("gorm:create").Register("tenant:create", stampTenant); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("gorm:query").Register("tenant:query", filterTenant); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("tenant:update", filterTenant); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("tenant:delete", filterTenant); err != nil {
+		return err
+	}
+	return callback.Row().Before("gorm:row").Register("tenant:row", filterTenant)
+}
+
+func filterTenant(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return
+	}
+	field := tx.Statement.Schema.LookUpField(tenantField)
+	if field == nil {
+		return
+	}
+	tenant, err := TenantFilter(tx.Statement.Context)
+	if err != nil {
+		_ = tx.AddError(err)
+		return
+	}
+	if tenant == "" {
+		return
+	}
+	tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenant},
+	}})
+}
+
+func stampTenant(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return
+	}
+	field := tx.Statement.Schema.LookUpField(tenantField)
+	if field == nil {
+		return
+	}
+	ctx := tx.Statement.Context
+	stamp := func(record reflect.Value) {
+		current, _ := field.ValueOf(ctx, record)
+		tenant, err := AssignTenant(ctx, current.(string))
+		if err == nil {
+			err = field.Set(ctx, record, tenant)
+		}
+		if err != nil {
+			_ = tx.AddError(err)
+		}
+	}
+	records := reflect.Indirect(tx.Statement.ReflectValue)
+	switch records.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < records.Len(); i++ {
+			stamp(reflect.Indirect(records.Index(i)))
+		}
+	case reflect.Struct:
+		stamp(records)
+	}
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// Operation is the kind of a database call, each kind having its own timeout.
+type Operation int
+
+const (
+	OperationRead Operation = iota
+	OperationWrite
+	OperationBulk
+)
+
+func (r *Resolver) Timeout(operation Operation) time.Duration {
+	return r.timeouts[operation]
+}
+
+// WithTimeout bounds ctx with the timeout of operation. A deadline already
+// closer is kept, and a zero timeout leaves ctx unbounded.
+func (r *Resolver) WithTimeout(ctx context.Context, operation Operation) (context.Context, context.CancelFunc) {
+	timeout := r.timeouts[operation]
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+const (
+	startKey     = "metrics:start"
+	unknownLabel = "unknown"
+)
+
+var queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "db_query_duration_seconds",
+	Help:      "Latency of the database statements, by repository method.",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"repository", "method", "operation", "status"})
+
+func init() {
+	prometheus.MustRegister(queryDuration)
+}
+
+// GormPlugin times every statement and labels it with the repository method
+// that issued it, found as the first caller in the repository package.
+type GormPlugin struct {
+	repoPackage string
+	// callers caches the repository and method of the program counters seen,
+	// nil for those outside the repository package.
+	callers sync.Map
+}
+
+func (p *GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (p *GormPlugin) Initialize(gormDB *gorm.DB) error {
+	callback := gormDB.Callback()
+	for _, err := range []error{
+		callback.Create().Before("*").Register("metrics:before_create", start),
+		callback.Create().After("*").Register("metrics:after_create", p.observe("create")),
+		callback.Query().Before("*").Register("metrics:before_query", start),
+		callback.Query().After("*").Register("metrics:after_query", p.observe("query")),
+		callback.Update().Before("*").Register("metrics:before_update", start),
+		callback.Update().After("*").Register("metrics:after_update", p.observe("update")),
+		callback.Delete().Before("*").Register("metrics:before_delete", start),
+		callback.Delete().After("*").Register("metrics:after_delete", p.observe("delete")),
+		callback.Row().Before("*").Register("metrics:before_row", start),
+		callback.Row().After("*").Register("metrics:after_row", p.observe("row")),
+		callback.Raw().Before("*").Register("metrics:before_raw", start),
+		callback.Raw().After("*").Register("metrics:after_raw", p.observe("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func start(tx *gorm.DB) {
+	tx.InstanceSet(startKey, time.Now())
+}
+
+func (p *GormPlugin) observe(operation string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		repository, method := p.caller()
+		ObserveQuery(repository, method, operation, value.(time.Time), tx.Error)
+	}
+}
+
+// ObserveQuery records a statement that began at begin, for the statements
+// run outside of GORM such as those of the pgx repository.
+func ObserveQuery(repository string, method string, operation string, begin time.Time, err error) {
+	status := "ok"
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		status = "error"
+	}
+	queryDuration.WithLabelValues(repository, method, operation, status).Observe(time.Since(begin).Seconds())
+}
+
+type caller struct {
+	repository string
+	method     string
+}
+
+func (p *GormPlugin) caller() (string, string) {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	for _, pc := range pcs[:n] {
+		if cached, ok := p.callers.Load(pc); ok {
+			if cached != nil {
+				c := cached.(*caller)
+				return c.repository, c.method
+			}
+			continue
+		}
+		c := p.resolve(pc)
+		if c == nil {
+			p.callers.Store(pc, nil)
+			continue
+		}
+		p.callers.Store(pc, c)
+		return c.repository, c.method
+	}
+	return unknownLabel, unknownLabel
+}
+
+// resolve turns repo.GormProductRepository.FindByID.func1, or
+// repo.(*MemoryProductRepository).Create, into its type and method.
+func (p *GormPlugin) resolve(pc uintptr) *caller {
+	fn := runtime.FuncForPC(pc - 1)
+	if fn == nil {
+		return nil
+	}
+	name, found := strings.CutPrefix(fn.Name(), p.repoPackage+".")
+	if !found {
+		return nil
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) < 2 {
+		return nil
+	}
+	repository := strings.TrimSuffix(strings.TrimPrefix(parts[0], "(*"), ")")
+	return &caller{repository: repository, method: parts[1]}
+}
+
+func NewGormPlugin(repoPackage string) *GormPlugin {
+	return &GormPlugin{repoPackage: repoPackage}
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "sample_crud"
+
+// RegisterDBStats exports the sql.DBStats of a connection pool as the go_sql_*
+// metrics, labelled db_name=name.
+func RegisterDBStats(name string, pool *sql.DB) {
+	register(collectors.NewDBStatsCollector(pool, name))
+}
+
+// RegisterRedisPool exports the pool statistics of a Redis client.
+func RegisterRedisPool(client redis.UniversalClient) {
+	register(newRedisPoolCollector(client))
+}
+
+// register replaces the collector already registered with the same metrics,
+// so that a pool opened again in the same process, by a test or a reconnect,
+// is exported instead of panicking.
+func register(collector prometheus.Collector) {
+	err := prometheus.Register(collector)
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		prometheus.Unregister(registered.ExistingCollector)
+		err = prometheus.Register(collector)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
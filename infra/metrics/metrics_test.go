@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestRegisterTwiceReplacesTheCollector(t *testing.T) {
+	first, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer first.Close()
+	second, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer second.Close()
+	second.SetMaxOpenConns(7)
+
+	RegisterDBStats("twice", first)
+	RegisterDBStats("twice", second)
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+	RegisterRedisPool(client)
+	RegisterRedisPool(client)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "go_sql_max_open_connections" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[0].GetValue() == "twice" && metric.GetGauge().GetValue() != 7 {
+				t.Fatalf("expected the second pool to be exported, got %v", metric.GetGauge().GetValue())
+			}
+		}
+		return
+	}
+	t.Fatal("expected go_sql_max_open_connections to be exported")
+}
+
+func TestObserveQueryCountsNotFoundAsOk(t *testing.T) {
+	begin := time.Now()
+
+	ObserveQuery("TestRepository", "FindByID", "query", begin, gorm.ErrRecordNotFound)
+	ObserveQuery("TestRepository", "FindByID", "query", begin, errors.New("connection reset"))
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	counts := map[string]uint64{}
+	for _, family := range families {
+		if family.GetName() != "sample_crud_db_query_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["repository"] == "TestRepository" {
+				counts[labels["status"]] += metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	if counts["ok"] != 1 || counts["error"] != 1 {
+		t.Fatalf("expected one ok and one error statement, got %v", counts)
+	}
+}
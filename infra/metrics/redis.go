@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisPoolCollector reads the pool statistics of the client on every scrape.
+type redisPoolCollector struct {
+	client redis.UniversalClient
+
+	hits         *prometheus.Desc
+	misses       *prometheus.Desc
+	timeouts     *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+	open         *prometheus.Desc
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	staleClosed  *prometheus.Desc
+}
+
+func newRedisPoolCollector(client redis.UniversalClient) *redisPoolCollector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "redis_pool", name), help, nil, nil)
+	}
+	return &redisPoolCollector{
+		client:       client,
+		hits:         desc("hits_total", "Number of times a free connection was found in the pool."),
+		misses:       desc("misses_total", "Number of times a free connection was not found in the pool."),
+		timeouts:     desc("timeouts_total", "Number of times waiting for a connection timed out."),
+		waitCount:    desc("wait_count_total", "Number of times a connection was waited for."),
+		waitDuration: desc("wait_duration_seconds_total", "Total time spent waiting for a connection."),
+		open:         desc("open_connections", "Number of connections in the pool."),
+		inUse:        desc("in_use_connections", "Number of connections in use."),
+		idle:         desc("idle_connections", "Number of idle connections."),
+		staleClosed:  desc("stale_closed_total", "Number of stale connections removed from the pool."),
+	}
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.staleClosed
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, time.Duration(stats.WaitDurationNs).Seconds())
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.TotalConns-min(stats.IdleConns, stats.TotalConns)))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleClosed, prometheus.CounterValue, float64(stats.StaleConns))
+}
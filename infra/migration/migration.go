@@ -0,0 +1,301 @@
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sample-crud/infra/db"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+//go:embed migrations/*/*.sql
+var embedded embed.FS
+
+const (
+	historyTable = "schema_migrations"
+	// lockID is an arbitrary key shared by every instance so that only one of
+	// them migrates at a time.
+	lockID = 7230412
+)
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+type appliedMigration struct {
+	version   int64
+	checksum  string
+	appliedAt time.Time
+}
+
+// NewMigrator loads the migrations written for driver, from its own directory
+// under migrations.
+func NewMigrator(sqlDB *sql.DB, driver string) (*Migrator, error) {
+	migrations, err := load(embedded, path.Join("migrations", driver))
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("no migration for database driver %q", driver)
+	}
+	return &Migrator{db: sqlDB, driver: driver, migrations: migrations}, nil
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	hasUp := map[int64]bool{}
+	for _, file := range files {
+		match := fileNamePattern.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", file)
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			hasUp[version] = true
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if !hasUp[m.Version] {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every pending migration, each one in its own transaction. It
+// refuses to run if an applied migration has been modified since.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		history, err := m.history(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if h, ok := history[migration.Version]; ok {
+				if h.checksum != migration.Checksum {
+					return fmt.Errorf("checksum mismatch for applied migration %d_%s", migration.Version, migration.Name)
+				}
+				continue
+			}
+			zap.L().Info("Applying migration", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+			if err := m.apply(ctx, conn, migration.Up, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, "INSERT INTO "+historyTable+" (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)",
+					migration.Version, migration.Name, migration.Checksum, time.Now())
+				return err
+			}); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		history, err := m.history(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := history[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+			}
+			zap.L().Info("Reverting migration", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+			if err := m.apply(ctx, conn, migration.Down, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, "DELETE FROM "+historyTable+" WHERE version = $1", migration.Version)
+				return err
+			}); err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	history, err := m.history(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if h, ok := history[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &h.appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, script string, record func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := record(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) history(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+historyTable+` (
+    version    bigint primary key,
+    name       varchar not null,
+    checksum   varchar not null,
+    applied_at timestamp not null
+)`); err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, "SELECT version, checksum, applied_at FROM "+historyTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	history := map[int64]appliedMigration{}
+	for rows.Next() {
+		var h appliedMigration
+		if err := rows.Scan(&h.version, &h.checksum, &h.appliedAt); err != nil {
+			return nil, err
+		}
+		history[h.version] = h
+	}
+	return history, rows.Err()
+}
+
+// withLock runs fn on a single connection holding a session advisory lock, so
+// that concurrent runs from several instances are serialized. SQLite has no
+// such lock and needs none, its database belonging to a single instance.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if m.driver == db.DriverSQLite {
+		return fn(conn)
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("fail to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID); err != nil {
+			zap.L().Warn("Fail to release migration lock", zap.Error(err))
+		}
+	}()
+	// Migrations may run far longer than the statement_timeout of the pool,
+	// lift it for this connection only.
+	if _, err := conn.ExecContext(ctx, "SET statement_timeout = 0"); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "RESET statement_timeout"); err != nil {
+			zap.L().Warn("Fail to reset statement timeout", zap.Error(err))
+		}
+	}()
+	return fn(conn)
+}
+
+// Create writes a pair of placeholder up and down files for the next version into
+// dir and returns their paths.
+func Create(dir string, name string) ([]string, error) {
+	if !regexp.MustCompile(`^\w+$`).MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q, only letters, digits and underscores are allowed", name)
+	}
+	migrations, err := load(os.DirFS(dir), ".")
+	if err != nil {
+		return nil, err
+	}
+	var next int64 = 1
+	if len(migrations) > 0 {
+		next = migrations[len(migrations)-1].Version + 1
+	}
+	base := fmt.Sprintf("%04d_%s", next, strings.ToLower(name))
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		file := filepath.Join(dir, base+"."+direction+".sql")
+		if err := os.WriteFile(file, []byte("-- "+base+" "+direction+"\n"), 0o644); err != nil {
+			return nil, err
+		}
+		paths = append(paths, file)
+	}
+	return paths, nil
+}
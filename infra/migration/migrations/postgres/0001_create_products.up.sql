@@ -1,4 +1,6 @@
-create table sample.products
+create schema if not exists sample;
+
+create table if not exists sample.products
 (
     id         SERIAL
         constraint products_pk
@@ -6,4 +8,4 @@ create table sample.products
     name       varchar,
     created_at timestamp,
     updated_at timestamp
-);
\ No newline at end of file
+);
@@ -0,0 +1 @@
+drop index if exists sample.products_updated_at_idx;
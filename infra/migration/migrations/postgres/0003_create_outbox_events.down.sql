@@ -0,0 +1 @@
+drop table if exists sample.outbox_events;
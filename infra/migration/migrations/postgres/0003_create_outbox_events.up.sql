@@ -0,0 +1,17 @@
+create table if not exists sample.outbox_events
+(
+    id              bigserial
+        constraint outbox_events_pk
+            primary key,
+    aggregate_type  varchar   not null,
+    aggregate_id    varchar   not null,
+    event_type      varchar   not null,
+    payload         jsonb     not null,
+    attempts        integer   not null default 0,
+    last_error      varchar,
+    next_attempt_at timestamp not null,
+    created_at      timestamp not null,
+    published_at    timestamp
+);
+
+create index if not exists outbox_events_pending_idx on sample.outbox_events (id) where published_at is null;
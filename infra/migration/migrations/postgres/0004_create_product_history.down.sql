@@ -0,0 +1 @@
+drop table if exists sample.product_history;
@@ -0,0 +1,15 @@
+create table if not exists sample.product_history
+(
+    id         bigserial
+        constraint product_history_pk
+            primary key,
+    product_id integer   not null,
+    operation  varchar   not null,
+    before     jsonb,
+    after      jsonb,
+    actor      varchar,
+    trace_id   varchar,
+    created_at timestamp not null
+);
+
+create index if not exists product_history_product_idx on sample.product_history (product_id, created_at desc, id desc);
@@ -0,0 +1,3 @@
+drop index if exists sample.products_sku_uindex;
+
+alter table sample.products drop column if exists sku;
@@ -0,0 +1,3 @@
+alter table sample.products add column if not exists sku varchar;
+
+create unique index if not exists products_sku_uindex on sample.products (sku);
@@ -0,0 +1,7 @@
+drop index if exists sample.products_tenant_idx;
+drop index if exists sample.products_tenant_sku_uindex;
+create unique index if not exists products_sku_uindex on sample.products (sku);
+
+alter table sample.outbox_events drop column if exists tenant_id;
+alter table sample.product_history drop column if exists tenant_id;
+alter table sample.products drop column if exists tenant_id;
@@ -0,0 +1,12 @@
+-- Existing rows move to the default tenant. The default is then dropped, the
+-- application always writes the tenant.
+alter table sample.products add column if not exists tenant_id varchar not null default 'default';
+alter table sample.products alter column tenant_id drop default;
+alter table sample.product_history add column if not exists tenant_id varchar not null default 'default';
+alter table sample.product_history alter column tenant_id drop default;
+alter table sample.outbox_events add column if not exists tenant_id varchar not null default 'default';
+alter table sample.outbox_events alter column tenant_id drop default;
+
+drop index if exists sample.products_sku_uindex;
+create unique index if not exists products_tenant_sku_uindex on sample.products (tenant_id, sku);
+create index if not exists products_tenant_idx on sample.products (tenant_id, id);
@@ -0,0 +1 @@
+alter table sample.product_history alter column created_at type timestamp using created_at::timestamp;
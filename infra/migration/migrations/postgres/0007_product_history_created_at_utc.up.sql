@@ -0,0 +1,4 @@
+-- History timestamps were written as local wall clock time. Existing rows are
+-- read in the TimeZone of the migrating session, which must be the one the
+-- application ran in.
+alter table sample.product_history alter column created_at type timestamptz using created_at::timestamptz;
@@ -0,0 +1 @@
+drop index if exists sample.outbox_events_pending_aggregate_idx;
@@ -0,0 +1,3 @@
+alter table sample.products
+    alter column created_at type timestamp using created_at::timestamp,
+    alter column updated_at type timestamp using updated_at::timestamp;
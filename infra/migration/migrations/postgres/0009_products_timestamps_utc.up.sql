@@ -0,0 +1,6 @@
+-- Product timestamps were written as local wall clock time. Existing rows are
+-- read in the TimeZone of the migrating session, which must be the one the
+-- application ran in.
+alter table sample.products
+    alter column created_at type timestamptz using created_at::timestamptz,
+    alter column updated_at type timestamptz using updated_at::timestamptz;
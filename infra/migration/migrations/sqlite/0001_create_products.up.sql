@@ -0,0 +1,9 @@
+create table if not exists products
+(
+    id         integer
+        constraint products_pk
+            primary key autoincrement,
+    name       varchar,
+    created_at timestamp,
+    updated_at timestamp
+);
@@ -0,0 +1 @@
+drop index if exists products_updated_at_idx;
@@ -0,0 +1 @@
+create index if not exists products_updated_at_idx on products (updated_at desc);
@@ -0,0 +1,17 @@
+create table if not exists outbox_events
+(
+    id              integer
+        constraint outbox_events_pk
+            primary key autoincrement,
+    aggregate_type  varchar   not null,
+    aggregate_id    varchar   not null,
+    event_type      varchar   not null,
+    payload         text      not null,
+    attempts        integer   not null default 0,
+    last_error      varchar,
+    next_attempt_at timestamp not null,
+    created_at      timestamp not null,
+    published_at    timestamp
+);
+
+create index if not exists outbox_events_pending_idx on outbox_events (id) where published_at is null;
@@ -0,0 +1,15 @@
+create table if not exists product_history
+(
+    id         integer
+        constraint product_history_pk
+            primary key autoincrement,
+    product_id integer   not null,
+    operation  varchar   not null,
+    before     text,
+    after      text,
+    actor      varchar,
+    trace_id   varchar,
+    created_at timestamp not null
+);
+
+create index if not exists product_history_product_idx on product_history (product_id, created_at desc, id desc);
@@ -0,0 +1,3 @@
+drop index if exists products_sku_uindex;
+
+alter table products drop column sku;
@@ -0,0 +1,3 @@
+alter table products add column sku varchar;
+
+create unique index if not exists products_sku_uindex on products (sku);
@@ -0,0 +1,7 @@
+drop index if exists products_tenant_idx;
+drop index if exists products_tenant_sku_uindex;
+create unique index if not exists products_sku_uindex on products (sku);
+
+alter table outbox_events drop column tenant_id;
+alter table product_history drop column tenant_id;
+alter table products drop column tenant_id;
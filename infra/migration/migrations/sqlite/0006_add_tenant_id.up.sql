@@ -0,0 +1,9 @@
+-- Existing rows move to the default tenant. SQLite cannot drop the default
+-- afterwards, the application always writes the tenant anyway.
+alter table products add column tenant_id varchar not null default 'default';
+alter table product_history add column tenant_id varchar not null default 'default';
+alter table outbox_events add column tenant_id varchar not null default 'default';
+
+drop index if exists products_sku_uindex;
+create unique index if not exists products_tenant_sku_uindex on products (tenant_id, sku);
+create index if not exists products_tenant_idx on products (tenant_id, id);
@@ -0,0 +1,4 @@
+-- History timestamps were written as local time with their offset, and are
+-- compared as text: rewrite them in UTC, at the millisecond precision of
+-- strftime.
+update product_history set created_at = strftime('%Y-%m-%d %H:%M:%f+00:00', created_at);
@@ -0,0 +1 @@
+drop index if exists outbox_events_pending_aggregate_idx;
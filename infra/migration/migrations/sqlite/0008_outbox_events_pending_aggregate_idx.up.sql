@@ -0,0 +1 @@
+create index if not exists outbox_events_pending_aggregate_idx on outbox_events (aggregate_type, aggregate_id, id) where published_at is null;
@@ -0,0 +1,2 @@
+-- UTC timestamps stay valid, only their time zone changes.
+select 1;
@@ -0,0 +1,6 @@
+-- Product timestamps were written as local time with their offset, and are
+-- compared as text: rewrite them in UTC, at the millisecond precision of
+-- strftime.
+update products
+set created_at = strftime('%Y-%m-%d %H:%M:%f+00:00', created_at),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', updated_at);
@@ -2,6 +2,9 @@ package config
 
 import (
 	"log"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,17 +15,27 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	Redis    RedisConfig
+	Cache    CacheConfig
 	Logger   LoggerConfig
 	Grpc     GrpcConfig
+	Admin    AdminConfig
+	Outbox   OutboxConfig
+	Tenant   TenantConfig
+
+	Idempotency IdempotencyConfig
 }
 
 type ServerConfig struct {
 	Host string
 	Port string
 	Mode string
+
+	ValidateOpenAPI bool
 }
 
 type DatabaseConfig struct {
+	Driver        string
+	DSN           string
 	Host          string
 	Port          string
 	Name          string
@@ -33,17 +46,50 @@ type DatabaseConfig struct {
 	MaxIdle       int
 	MaxLifetime   time.Duration
 	MaxIdleTime   time.Duration
+	TxIsolation   string
+	TxMaxRetries  int
+	AutoMigrate   bool
+	Repository    string
+
+	ConnectRetries    int
+	ConnectBackoff    time.Duration
+	ConnectMaxBackoff time.Duration
+	RetryMaxAttempts  int
+	RetryBudget       int
+
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	BulkTimeout  time.Duration
+
+	SlowQueryThreshold time.Duration
+
+	ReplicaHosts       []string
+	ReplicaHealthEvery time.Duration
 }
 
 type RedisConfig struct {
-	Host     string
-	Port     string
-	Password string
-	DB       int
-	PoolSize int
-	MinIdle  int
-	MaxWait  time.Duration
-	IdleTime time.Duration
+	Mode             string
+	Host             string
+	Port             string
+	Addrs            []string
+	MasterName       string
+	SentinelPassword string
+	Password         string
+	DB               int
+	PoolSize         int
+	MinIdle          int
+	MaxWait          time.Duration
+	IdleTime         time.Duration
+	CallTimeout      time.Duration
+	BreakerThreshold int
+	ReconnectEvery   time.Duration
+}
+
+type CacheConfig struct {
+	WarmupEnabled  bool
+	WarmupSize     int
+	WarmupFile     string
+	WarmupDeadline time.Duration
 }
 
 type LoggerConfig struct {
@@ -55,6 +101,35 @@ type GrpcConfig struct {
 	Port int
 }
 
+type OutboxConfig struct {
+	Enabled         bool
+	Sink            string
+	Stream          string
+	StreamMaxLen    int64
+	BatchSize       int
+	PollInterval    time.Duration
+	Lease           time.Duration
+	MaxBackoff      time.Duration
+	Retention       time.Duration
+	CleanupInterval time.Duration
+}
+
+type AdminConfig struct {
+	Token string
+}
+
+type IdempotencyConfig struct {
+	TTL         time.Duration
+	LockTimeout time.Duration
+}
+
+type TenantConfig struct {
+	Required  bool
+	Default   string
+	JWTSecret string
+	JWTClaim  string
+}
+
 func Load() *Config {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
@@ -64,8 +139,12 @@ func Load() *Config {
 			Host: env.GetEnv("SERVER_HOST", "localhost"),
 			Port: env.GetEnv("SERVER_PORT", "8080"),
 			Mode: env.GetEnv("SERVER_MODE", "release"),
+
+			ValidateOpenAPI: getEnvAsBool("SERVER_VALIDATE_OPENAPI", false),
 		},
 		Database: DatabaseConfig{
+			Driver:        env.GetEnv("DATABASE_DRIVER", "postgres"),
+			DSN:           env.GetEnv("DATABASE_DSN", "sample.db"),
 			Host:          env.GetEnv("DATABASE_HOST", "localhost"),
 			Port:          env.GetEnv("DATABASE_PORT", "5432"),
 			Name:          env.GetEnv("DATABASE_NAME", "postgres"),
@@ -76,16 +155,48 @@ func Load() *Config {
 			MaxIdle:       env.GetEnvAsInt("DATABASE_MAX_IDLE", 5),
 			MaxLifetime:   env.GetEnvAsDuration("DATABASE_MAX_LIFETIME", time.Minute*30),
 			MaxIdleTime:   env.GetEnvAsDuration("DATABASE_MAX_IDLE_TIME", time.Minute*10),
+			TxIsolation:   env.GetEnv("DATABASE_TX_ISOLATION", "read_committed"),
+			TxMaxRetries:  env.GetEnvAsInt("DATABASE_TX_MAX_RETRIES", 3),
+			AutoMigrate:   getEnvAsBool("DATABASE_AUTO_MIGRATE", false),
+			Repository:    env.GetEnv("DATABASE_REPOSITORY", "gorm"),
+
+			ConnectRetries:    env.GetEnvAsInt("DATABASE_CONNECT_RETRIES", 10),
+			ConnectBackoff:    env.GetEnvAsDuration("DATABASE_CONNECT_BACKOFF", time.Millisecond*500),
+			ConnectMaxBackoff: env.GetEnvAsDuration("DATABASE_CONNECT_MAX_BACKOFF", time.Second*30),
+			RetryMaxAttempts:  env.GetEnvAsInt("DATABASE_RETRY_MAX_ATTEMPTS", 3),
+			RetryBudget:       env.GetEnvAsInt("DATABASE_RETRY_BUDGET", 10),
+
+			ReadTimeout:  env.GetEnvAsDuration("DATABASE_READ_TIMEOUT", time.Second*3),
+			WriteTimeout: env.GetEnvAsDuration("DATABASE_WRITE_TIMEOUT", time.Second*5),
+			BulkTimeout:  env.GetEnvAsDuration("DATABASE_BULK_TIMEOUT", time.Second*30),
+
+			SlowQueryThreshold: env.GetEnvAsDuration("DATABASE_SLOW_QUERY_THRESHOLD", time.Millisecond*200),
+
+			ReplicaHosts:       getEnvAsSlice("DATABASE_REPLICA_HOSTS", nil),
+			ReplicaHealthEvery: env.GetEnvAsDuration("DATABASE_REPLICA_HEALTH_INTERVAL", time.Second*10),
 		},
 		Redis: RedisConfig{
-			Host:     env.GetEnv("REDIS_HOST", "localhost"),
-			Port:     env.GetEnv("REDIS_PORT", "6379"),
-			Password: env.GetEnv("REDIS_PASSWORD", ""),
-			DB:       env.GetEnvAsInt("REDIS_DB", 0),
-			PoolSize: env.GetEnvAsInt("REDIS_POOL_SIZE", 20),
-			MinIdle:  env.GetEnvAsInt("REDIS_MIN_IDLE", 5),
-			MaxWait:  env.GetEnvAsDuration("REDIS_MAX_WAIT", time.Second*5),
-			IdleTime: env.GetEnvAsDuration("REDIS_IDLE_TIME", time.Minute*30),
+			Mode:             env.GetEnv("REDIS_MODE", "standalone"),
+			Host:             env.GetEnv("REDIS_HOST", "localhost"),
+			Port:             env.GetEnv("REDIS_PORT", "6379"),
+			Addrs:            getEnvAsSlice("REDIS_ADDRS", nil),
+			MasterName:       env.GetEnv("REDIS_MASTER_NAME", ""),
+			SentinelPassword: env.GetEnv("REDIS_SENTINEL_PASSWORD", ""),
+			Password:         env.GetEnv("REDIS_PASSWORD", ""),
+			DB:               env.GetEnvAsInt("REDIS_DB", 0),
+			PoolSize:         env.GetEnvAsInt("REDIS_POOL_SIZE", 20),
+			MinIdle:          env.GetEnvAsInt("REDIS_MIN_IDLE", 5),
+			MaxWait:          env.GetEnvAsDuration("REDIS_MAX_WAIT", time.Second*5),
+			IdleTime:         env.GetEnvAsDuration("REDIS_IDLE_TIME", time.Minute*30),
+			CallTimeout:      env.GetEnvAsDuration("REDIS_CALL_TIMEOUT", time.Millisecond*100),
+			BreakerThreshold: env.GetEnvAsInt("REDIS_BREAKER_THRESHOLD", 5),
+			ReconnectEvery:   env.GetEnvAsDuration("REDIS_RECONNECT_INTERVAL", time.Second*5),
+		},
+		Cache: CacheConfig{
+			WarmupEnabled:  getEnvAsBool("CACHE_WARMUP_ENABLED", false),
+			WarmupSize:     env.GetEnvAsInt("CACHE_WARMUP_SIZE", 1000),
+			WarmupFile:     env.GetEnv("CACHE_WARMUP_FILE", ""),
+			WarmupDeadline: env.GetEnvAsDuration("CACHE_WARMUP_DEADLINE", time.Second*10),
 		},
 		Logger: LoggerConfig{
 			Level:  env.GetEnv("LOG_LEVEL", "info"),
@@ -94,5 +205,51 @@ func Load() *Config {
 		Grpc: GrpcConfig{
 			Port: env.GetEnvAsInt("GRPC_PORT", 9090),
 		},
+		Outbox: OutboxConfig{
+			Enabled:         getEnvAsBool("OUTBOX_ENABLED", true),
+			Sink:            env.GetEnv("OUTBOX_SINK", "log"),
+			Stream:          env.GetEnv("OUTBOX_STREAM", "sample_crud:events"),
+			StreamMaxLen:    int64(env.GetEnvAsInt("OUTBOX_STREAM_MAX_LEN", 100000)),
+			BatchSize:       env.GetEnvAsInt("OUTBOX_BATCH_SIZE", 100),
+			PollInterval:    env.GetEnvAsDuration("OUTBOX_POLL_INTERVAL", time.Second),
+			Lease:           env.GetEnvAsDuration("OUTBOX_LEASE", time.Second*30),
+			MaxBackoff:      env.GetEnvAsDuration("OUTBOX_MAX_BACKOFF", time.Minute*5),
+			Retention:       env.GetEnvAsDuration("OUTBOX_RETENTION", time.Hour*24*7),
+			CleanupInterval: env.GetEnvAsDuration("OUTBOX_CLEANUP_INTERVAL", time.Hour),
+		},
+		Admin: AdminConfig{
+			Token: env.GetEnv("ADMIN_TOKEN", ""),
+		},
+		Tenant: TenantConfig{
+			Required:  getEnvAsBool("TENANT_REQUIRED", false),
+			Default:   env.GetEnv("TENANT_DEFAULT", "default"),
+			JWTSecret: env.GetEnv("TENANT_JWT_SECRET", ""),
+			JWTClaim:  env.GetEnv("TENANT_JWT_CLAIM", "tenant_id"),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:         env.GetEnvAsDuration("IDEMPOTENCY_TTL", time.Hour*24),
+			LockTimeout: env.GetEnvAsDuration("IDEMPOTENCY_LOCK_TIMEOUT", time.Minute),
+		},
+	}
+}
+
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
 	}
+	return defaultValue
 }
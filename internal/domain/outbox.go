@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+const (
+	AggregateProduct = "product"
+
+	EventProductCreated = "product.created"
+	EventProductUpdated = "product.updated"
+	EventProductDeleted = "product.deleted"
+)
+
+type OutboxEvent struct {
+	ID            uint64 `gorm:"primaryKey"`
+	TenantID      string
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	Payload       string `gorm:"type:jsonb"`
+	Attempts      int
+	LastError     *string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	PublishedAt   *time.Time
+}
+
+func (e OutboxEvent) TableName(namer schema.Namer) string {
+	return namer.TableName("outbox_events")
+}
+
+type ProductEvent struct {
+	ID         uint      `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	Name       string    `json:"name,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
@@ -2,17 +2,21 @@ package domain
 
 import (
 	"time"
+
+	"gorm.io/gorm/schema"
 )
 
 type Product struct {
 	ID        uint `gorm:"primaryKey"`
+	TenantID  string
 	Name      string
+	SKU       *string    `gorm:"column:sku"`
 	CreatedAt *time.Time // với gorm luôn nên để con trỏ để có giá trị nil, nếu không sẽ insert giờ mặc định
 	UpdatedAt *time.Time
 }
 
-func (p Product) TableName() string {
-	return "sample.products" // implement interface Tabler chỉ định schema và table name
+func (p Product) TableName(namer schema.Namer) string {
+	return namer.TableName("products") // implement interface TablerWithNamer, schema do kết nối chỉ định
 }
 
 type ProductCreation struct {
@@ -26,4 +30,114 @@ type ProductUpdate struct {
 type ProductInfo struct {
 	ID   uint   `json:"id"`
 	Name string `json:"name"`
+	SKU  string `json:"sku,omitempty"`
+	// UpdatedAt is not part of the body, it backs the ETag and Last-Modified
+	// headers.
+	UpdatedAt *time.Time `json:"-"`
+}
+
+func NewProductInfo(product *Product) ProductInfo {
+	info := ProductInfo{
+		ID:        product.ID,
+		Name:      product.Name,
+		UpdatedAt: product.UpdatedAt,
+	}
+	if product.SKU != nil {
+		info.SKU = *product.SKU
+	}
+	return info
+}
+
+type ProductListQuery struct {
+	Query string `form:"q"`
+	Page  int    `form:"page,default=1" binding:"min=1"`
+	Size  int    `form:"size,default=20" binding:"min=1,max=100"`
+}
+
+type ProductPage struct {
+	Items []ProductInfo `json:"items"`
+	Page  int           `json:"page"`
+	Size  int           `json:"size"`
+	Total int64         `json:"total"`
+}
+
+type CacheEntry struct {
+	Key           string `json:"key"`
+	Exists        bool   `json:"exists"`
+	Value         string `json:"value,omitempty"`
+	TTLSeconds    int64  `json:"ttl_seconds"`
+	SchemaVersion int    `json:"schema_version,omitempty"`
+}
+
+type CacheEviction struct {
+	Deleted int64 `json:"deleted"`
+}
+
+type ProductUpsert struct {
+	SKU  string `json:"sku" binding:"required,max=64"`
+	Name string `json:"name" binding:"required,min=3"`
+}
+
+type BatchCreateRequest struct {
+	Items []ProductCreation `json:"items" binding:"required,min=1,max=1000"`
+}
+
+type BatchUpsertRequest struct {
+	Items []ProductUpsert `json:"items" binding:"required,min=1,max=1000"`
+}
+
+type BatchDeleteRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=1000"`
+}
+
+type BatchItemResult struct {
+	Index     int    `json:"index"`
+	ID        uint   `json:"id,omitempty"`
+	Success   bool   `json:"success"`
+	ErrorCode string `json:"error_code,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type BatchResult struct {
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Items     []BatchItemResult `json:"items"`
+}
+
+func NewBatchResult(items []BatchItemResult) *BatchResult {
+	result := &BatchResult{Items: items}
+	for _, item := range items {
+		if item.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result
+}
+
+// ProductImportRow is a row of an imported file, Row being its line number.
+// Rows with a sku are upserted, the others created.
+type ProductImportRow struct {
+	Row  int
+	SKU  string
+	Name string
+}
+
+type ProductImportError struct {
+	Row       int    `json:"row"`
+	SKU       string `json:"sku,omitempty"`
+	Name      string `json:"name"`
+	ErrorCode string `json:"error_code"`
+	Error     string `json:"error"`
+}
+
+// ProductImportResult lists the first rejected rows, all of them being in the
+// report of ReportID, or every rejected row when the report could not be saved.
+type ProductImportResult struct {
+	Total     int                  `json:"total"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	ReportID  string               `json:"report_id,omitempty"`
+	Errors    []ProductImportError `json:"errors,omitempty"`
 }
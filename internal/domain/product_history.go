@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+type ProductHistory struct {
+	ID        uint64 `gorm:"primaryKey"`
+	TenantID  string
+	ProductID uint
+	Operation string
+	Before    *string `gorm:"type:jsonb"`
+	After     *string `gorm:"type:jsonb"`
+	Actor     string
+	TraceID   string
+	CreatedAt time.Time
+}
+
+func (h ProductHistory) TableName(namer schema.Namer) string {
+	return namer.TableName("product_history")
+}
+
+type ProductSnapshot struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	SKU       *string    `json:"sku,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func NewProductSnapshot(product *Product) *ProductSnapshot {
+	if product == nil {
+		return nil
+	}
+	return &ProductSnapshot{
+		ID:        product.ID,
+		Name:      product.Name,
+		SKU:       product.SKU,
+		CreatedAt: product.CreatedAt,
+		UpdatedAt: product.UpdatedAt,
+	}
+}
+
+type PageQuery struct {
+	Page int `form:"page,default=1" binding:"min=1"`
+	Size int `form:"size,default=20" binding:"min=1,max=100"`
+}
+
+type ProductHistoryInfo struct {
+	ID        uint64          `json:"id"`
+	ProductID uint            `json:"product_id"`
+	Operation string          `json:"operation"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	Actor     string          `json:"actor,omitempty"`
+	TraceID   string          `json:"trace_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type ProductHistoryPage struct {
+	Items []ProductHistoryInfo `json:"items"`
+	Page  int                  `json:"page"`
+	Size  int                  `json:"size"`
+	Total int64                `json:"total"`
+}
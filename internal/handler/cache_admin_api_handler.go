@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"sample-crud/internal/service"
+	customerrors "sample-crud/pkg/errors"
+	"sample-crud/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type CacheAdminApiHandler struct {
+	cacheAdminService service.CacheAdminService
+}
+
+func NewCacheAdminApiHandler(cacheAdminService service.CacheAdminService) *CacheAdminApiHandler {
+	return &CacheAdminApiHandler{
+		cacheAdminService: cacheAdminService,
+	}
+}
+
+func (h *CacheAdminApiHandler) InspectProduct(c *gin.Context) {
+	l := logger.GetLogger(c.Request.Context())
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		l.Warn("Invalid product id", zap.String("id", c.Param("id")), zap.Error(err))
+		c.Error(customerrors.NewBadRequestError("Invalid product id", err.Error()))
+		return
+	}
+	entry, err := h.cacheAdminService.InspectProduct(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(entry))
+}
+
+func (h *CacheAdminApiHandler) EvictProduct(c *gin.Context) {
+	l := logger.GetLogger(c.Request.Context())
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		l.Warn("Invalid product id", zap.String("id", c.Param("id")), zap.Error(err))
+		c.Error(customerrors.NewBadRequestError("Invalid product id", err.Error()))
+		return
+	}
+	eviction, err := h.cacheAdminService.EvictProduct(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(eviction))
+}
+
+func (h *CacheAdminApiHandler) EvictPattern(c *gin.Context) {
+	pattern := c.Query("pattern")
+	if pattern == "" {
+		c.Error(customerrors.NewBadRequestError("Pattern is required", "missing pattern query parameter"))
+		return
+	}
+	eviction, err := h.cacheAdminService.EvictPattern(c.Request.Context(), pattern)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(eviction))
+}
+
+// EvictTag takes the tag without its tenant, product:1 or list:all, the tenant
+// being that of the request.
+func (h *CacheAdminApiHandler) EvictTag(c *gin.Context) {
+	eviction, err := h.cacheAdminService.EvictTag(c.Request.Context(), c.Param("tag"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(eviction))
+}
+
+// FlushProductCache deletes the product entries, list pages and tags of every
+// tenant. Idempotency keys and import reports share the namespace but are kept.
+func (h *CacheAdminApiHandler) FlushProductCache(c *gin.Context) {
+	eviction, err := h.cacheAdminService.FlushProductCache(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(eviction))
+}
@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"sample-crud/internal/requestctx"
+	"sample-crud/internal/service"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newCachedProduct(t *testing.T) (*miniredis.Miniredis, service.ProductService, service.CacheAdminService, context.Context, uint) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+	productService := newProductServiceOn(t, redisClient, nil)
+	ctx := requestctx.WithTenant(context.Background(), "default")
+	id, err := productService.CreateProduct(ctx, "Keyboard")
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	if _, err := productService.FindByID(ctx, id); err != nil {
+		t.Fatalf("find product: %v", err)
+	}
+	return server, productService, service.NewCacheAdminService(redisClient), ctx, id
+}
+
+func TestInspectReportsThePayloadSchemaVersion(t *testing.T) {
+	_, _, cacheAdmin, ctx, id := newCachedProduct(t)
+
+	entry, err := cacheAdmin.InspectProduct(ctx, id)
+
+	if err != nil || !entry.Exists || entry.SchemaVersion != 1 {
+		t.Fatalf("expected a cached product with schema version 1, got %+v, %v", entry, err)
+	}
+}
+
+func TestProductCacheOfAnotherSchemaVersionIsAMiss(t *testing.T) {
+	server, productService, _, ctx, id := newCachedProduct(t)
+	server.Set(fmt.Sprintf("sample_crud:default:product#%d", id), fmt.Sprintf(`{"id":%d,"name":"Stale"}`, id))
+
+	product, err := productService.FindByID(ctx, id)
+
+	if err != nil || product.Name != "Keyboard" {
+		t.Fatalf("expected the product loaded from the database, got %+v, %v", product, err)
+	}
+}
+
+func TestFlushKeepsWhatIsNotCacheData(t *testing.T) {
+	server, _, cacheAdmin, ctx, _ := newCachedProduct(t)
+	kept := []string{
+		"sample_crud:default:idempotency#key-1",
+		"sample_crud:default:product_import_report#report-1",
+	}
+	for _, key := range kept {
+		server.Set(key, "{}")
+	}
+
+	eviction, err := cacheAdmin.FlushProductCache(ctx)
+
+	if err != nil || eviction.Deleted == 0 {
+		t.Fatalf("expected product cache entries flushed, got %+v, %v", eviction, err)
+	}
+	for _, key := range server.Keys() {
+		if !slices.Contains(kept, key) && !strings.HasPrefix(key, "sample_crud:tag_version#") {
+			t.Errorf("expected %s flushed", key)
+		}
+	}
+	for _, key := range kept {
+		if !server.Exists(key) {
+			t.Errorf("expected %s kept", key)
+		}
+	}
+}
+
+func TestEvictTagIsScopedToTheTenant(t *testing.T) {
+	server, _, cacheAdmin, ctx, id := newCachedProduct(t)
+
+	eviction, err := cacheAdmin.EvictTag(ctx, fmt.Sprintf("product:%d", id))
+
+	if err != nil || eviction.Deleted == 0 {
+		t.Fatalf("expected the product entries evicted, got %+v, %v", eviction, err)
+	}
+	if server.Exists(fmt.Sprintf("sample_crud:default:product#%d", id)) {
+		t.Fatal("expected the product cache entry deleted")
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sample-crud/pkg/response"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// isConditional tells whether the request carries validators to compare.
+func isConditional(r *http.Request) bool {
+	return r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != ""
+}
+
+// productETag derives the entity tag of a product from its update time, at
+// the microsecond precision Postgres keeps, and from the format of its
+// representation, the bytes of each format differing.
+func productETag(id uint, updatedAt time.Time, format string) string {
+	return fmt.Sprintf("\"%d-%x-%s\"", id, updatedAt.Truncate(time.Microsecond).UnixMicro(), format)
+}
+
+// representationFormat names the format negotiated from the Accept header,
+// both msgpack media types giving the same bytes.
+func representationFormat(c *gin.Context) string {
+	switch c.NegotiateFormat(response.Formats...) {
+	case binding.MIMEPROTOBUF:
+		return "protobuf"
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		return "msgpack"
+	default:
+		return "json"
+	}
+}
+
+// checkNotModified sets the ETag and Last-Modified headers of a product, and
+// answers 304 Not Modified when the request validators match them.
+// If-None-Match takes precedence over If-Modified-Since. The representation
+// depends on the Accept header, which Vary tells caches.
+func checkNotModified(c *gin.Context, id uint, updatedAt time.Time) bool {
+	etag := productETag(id, updatedAt, representationFormat(c))
+	c.Header("Vary", "Accept")
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+	notModified := false
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		notModified = etagMatches(match, etag)
+	} else if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil {
+		notModified = !updatedAt.Truncate(time.Second).After(since)
+	}
+	if notModified {
+		c.Status(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// etagMatches compares tags weakly, as If-None-Match requires.
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package handler_test
+
+import (
+	"net/http"
+	"sample-crud/internal/config"
+	"testing"
+)
+
+func TestETagDependsOnTheNegotiatedFormat(t *testing.T) {
+	router := newRouter(newProductService(t, nil), config.TenantConfig{Default: "default"})
+	path := createProduct(t, router, nil)
+
+	first := serve(router, http.MethodGet, path, "", map[string]string{"Accept": "application/json"})
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("Vary") != "Accept" {
+		t.Fatalf("expected a JSON product with an ETag varying on Accept, got %d %v", first.Code, first.Header())
+	}
+
+	recorder := serve(router, http.MethodGet, path, "", map[string]string{"Accept": "application/json", "If-None-Match": etag})
+	if recorder.Code != http.StatusNotModified || recorder.Header().Get("Vary") != "Accept" {
+		t.Fatalf("expected 304 varying on Accept for the same format, got %d %v", recorder.Code, recorder.Header())
+	}
+
+	recorder = serve(router, http.MethodGet, path, "", map[string]string{"Accept": "application/x-protobuf", "If-None-Match": etag})
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("expected the protobuf representation, got %d %v", recorder.Code, recorder.Header())
+	}
+	if recorder.Header().Get("ETag") == etag {
+		t.Fatalf("expected protobuf and JSON to have different ETags, both got %s", etag)
+	}
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// swaggerInitializer replaces the one of the swagger-ui distribution, which
+// points to the petstore example.
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+type DocsHandler struct {
+	spec  []byte
+	files http.Handler
+}
+
+func NewDocsHandler(doc *openapi3.T) (*DocsHandler, error) {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &DocsHandler{
+		spec:  spec,
+		files: http.StripPrefix("/docs", http.FileServer(http.FS(swaggerFiles.FS))),
+	}, nil
+}
+
+func (h *DocsHandler) OpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", h.spec)
+}
+
+// UI serves swagger-ui under /docs/.
+func (h *DocsHandler) UI(c *gin.Context) {
+	if c.Param("filepath") == "/swagger-initializer.js" {
+		c.Data(http.StatusOK, "application/javascript", []byte(swaggerInitializer))
+		return
+	}
+	h.files.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,89 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sample-crud/internal/config"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/repo"
+	"sample-crud/internal/requestctx"
+	"sample-crud/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// failingOutbox fails every append, for the writes publishing events to be
+// rolled back.
+type failingOutbox struct {
+	repo.OutboxRepository
+}
+
+func (failingOutbox) Append(context.Context, *domain.OutboxEvent) error {
+	return errors.New("outbox unavailable")
+}
+
+// newMemoryProductService runs the service on the in-memory repositories,
+// their events stored by the repository wrap returns, nil keeping the
+// in-memory one.
+func newMemoryProductService(t *testing.T, wrap func(repo.OutboxRepository) repo.OutboxRepository) (service.ProductService, *repo.MemoryOutboxRepository) {
+	t.Helper()
+	redisClient := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+	productRepo := repo.NewMemoryProductRepository()
+	outboxRepo := repo.NewMemoryOutboxRepository()
+	historyRepo := repo.NewMemoryProductHistoryRepository()
+	var outbox repo.OutboxRepository = outboxRepo
+	if wrap != nil {
+		outbox = wrap(outboxRepo)
+	}
+	txManager := repo.NewMemoryTransactionManager(productRepo, outboxRepo, historyRepo)
+	return service.NewProductService(productRepo, outbox, historyRepo, txManager, redisClient), outboxRepo
+}
+
+func TestProductServiceOnMemoryRepositories(t *testing.T) {
+	productService, outboxRepo := newMemoryProductService(t, nil)
+	router := newRouter(productService, config.TenantConfig{Default: "default"})
+
+	path := createProduct(t, router, nil)
+	time.Sleep(10 * time.Millisecond)
+	beforeUpdate := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	if recorder := serve(router, http.MethodPut, path, `{"name":"Mouse"}`, nil); recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200 updating, got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	asOf := url.QueryEscape(beforeUpdate.Format(time.RFC3339Nano))
+	if recorder := serve(router, http.MethodGet, path+"?as_of="+asOf, "", nil); recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "Keyboard") {
+		t.Fatalf("expected the product before its update, got %d: %s", recorder.Code, recorder.Body)
+	}
+	events, err := outboxRepo.FetchPending(requestctx.WithAllTenants(context.Background()), 10, time.Now())
+	if err != nil || len(events) != 1 || events[0].EventType != domain.EventProductCreated {
+		t.Fatalf("expected the creation event pending before the update one, got %v, %v", events, err)
+	}
+}
+
+func TestMemoryTransactionIsRolledBack(t *testing.T) {
+	productService, _ := newMemoryProductService(t, func(repo.OutboxRepository) repo.OutboxRepository {
+		return failingOutbox{}
+	})
+	ctx := requestctx.WithTenant(context.Background(), "default")
+
+	if _, err := productService.CreateProduct(ctx, "Keyboard"); err == nil {
+		t.Fatal("expected the creation to fail with the outbox")
+	}
+
+	page, err := productService.ListProducts(ctx, domain.ProductListQuery{Page: 1, Size: 10})
+	if err != nil || page.Total != 0 {
+		t.Fatalf("expected no product, got %+v, %v", page, err)
+	}
+	history, err := productService.FindHistory(ctx, 1, domain.PageQuery{Page: 1, Size: 10})
+	if err != nil || history.Total != 0 {
+		t.Fatalf("expected no history, got %+v, %v", history, err)
+	}
+}
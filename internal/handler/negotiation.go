@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"sample-crud/internal/domain"
+	"sample-crud/pkg/response"
+	"sample-crud/proto/pb/product"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// bindBody decodes the request body following its Content-Type: protobuf,
+// msgpack or, by default, JSON.
+func bindBody(c *gin.Context, request interface{}) error {
+	switch c.ContentType() {
+	case binding.MIMEPROTOBUF:
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		if err := fromProtoRequest(body, request); err != nil {
+			return err
+		}
+		return binding.Validator.ValidateStruct(request)
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		// The msgpack decoder fails on readers returning their last bytes
+		// along with io.EOF, as request bodies do, so hand it the whole body.
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		if len(body) == 0 {
+			return io.EOF
+		}
+		return binding.MsgPack.BindBody(body, request)
+	default:
+		return c.ShouldBindJSON(request)
+	}
+}
+
+// fromProtoRequest decodes body as the protobuf message of request and copies
+// it over.
+func fromProtoRequest(body []byte, request interface{}) error {
+	switch request := request.(type) {
+	case *domain.ProductCreation:
+		var message product.ProductCreation
+		if err := proto.Unmarshal(body, &message); err != nil {
+			return err
+		}
+		request.Name = message.GetName()
+	case *domain.ProductUpdate:
+		var message product.ProductUpdate
+		if err := proto.Unmarshal(body, &message); err != nil {
+			return err
+		}
+		request.Name = message.GetName()
+	case *domain.BatchCreateRequest:
+		var message product.BatchCreateRequest
+		if err := proto.Unmarshal(body, &message); err != nil {
+			return err
+		}
+		for _, item := range message.GetItems() {
+			request.Items = append(request.Items, domain.ProductCreation{Name: item.GetName()})
+		}
+	case *domain.BatchUpsertRequest:
+		var message product.BatchUpsertRequest
+		if err := proto.Unmarshal(body, &message); err != nil {
+			return err
+		}
+		for _, item := range message.GetItems() {
+			request.Items = append(request.Items, domain.ProductUpsert{SKU: item.GetSku(), Name: item.GetName()})
+		}
+	case *domain.BatchDeleteRequest:
+		var message product.BatchDeleteRequest
+		if err := proto.Unmarshal(body, &message); err != nil {
+			return err
+		}
+		for _, id := range message.GetIds() {
+			request.IDs = append(request.IDs, uint(id))
+		}
+	default:
+		return fmt.Errorf("no protobuf message for %T", request)
+	}
+	return nil
+}
+
+// render writes body in the format negotiated from the Accept header, JSON by
+// default.
+func render(c *gin.Context, status int, body response.BaseResponse) {
+	response.Render(c, status, body, func() (proto.Message, error) {
+		return toProtoResponse(body)
+	})
+}
+
+func toProtoResponse(body response.BaseResponse) (*product.BaseResponse, error) {
+	message := &product.BaseResponse{ResponseCode: body.ResponseCode, ResponseMessage: body.ResponseMessage}
+	switch data := body.Data.(type) {
+	case nil:
+	case response.CreatedData:
+		message.Data = &product.BaseResponse_Created{Created: &product.CreatedData{Id: uint64(data.ID)}}
+	case *domain.ProductInfo:
+		message.Data = &product.BaseResponse_Product{Product: toProtoProduct(data)}
+	case *domain.ProductPage:
+		page := &product.ProductPage{
+			Items: make([]*product.GetProductResponse, 0, len(data.Items)),
+			Page:  int32(data.Page),
+			Size:  int32(data.Size),
+			Total: data.Total,
+		}
+		for i := range data.Items {
+			page.Items = append(page.Items, toProtoProduct(&data.Items[i]))
+		}
+		message.Data = &product.BaseResponse_Products{Products: page}
+	case *domain.ProductHistoryPage:
+		message.Data = &product.BaseResponse_History{History: toProtoHistory(data)}
+	case *domain.BatchResult:
+		result := &product.BatchResult{
+			Succeeded: int32(data.Succeeded),
+			Failed:    int32(data.Failed),
+			Items:     make([]*product.BatchItemResult, 0, len(data.Items)),
+		}
+		for _, item := range data.Items {
+			result.Items = append(result.Items, &product.BatchItemResult{
+				Index:     int32(item.Index),
+				Id:        uint64(item.ID),
+				Success:   item.Success,
+				ErrorCode: item.ErrorCode,
+				Error:     item.Error,
+			})
+		}
+		message.Data = &product.BaseResponse_Batch{Batch: result}
+	case *domain.ProductImportResult:
+		result := &product.ProductImportResult{
+			Total:     int32(data.Total),
+			Succeeded: int32(data.Succeeded),
+			Failed:    int32(data.Failed),
+			ReportId:  data.ReportID,
+			Errors:    make([]*product.ProductImportError, 0, len(data.Errors)),
+		}
+		for _, rejected := range data.Errors {
+			result.Errors = append(result.Errors, &product.ProductImportError{
+				Row:       int32(rejected.Row),
+				Sku:       rejected.SKU,
+				Name:      rejected.Name,
+				ErrorCode: rejected.ErrorCode,
+				Error:     rejected.Error,
+			})
+		}
+		message.Data = &product.BaseResponse_ImportResult{ImportResult: result}
+	default:
+		return nil, fmt.Errorf("no protobuf message for %T", body.Data)
+	}
+	return message, nil
+}
+
+func toProtoProduct(productInfo *domain.ProductInfo) *product.GetProductResponse {
+	return &product.GetProductResponse{
+		Id:   uint64(productInfo.ID),
+		Name: productInfo.Name,
+		Sku:  productInfo.SKU,
+	}
+}
+
+func toProtoHistory(page *domain.ProductHistoryPage) *product.ListProductHistoryResponse {
+	resp := &product.ListProductHistoryResponse{
+		Items: make([]*product.ProductHistoryEntry, 0, len(page.Items)),
+		Page:  int32(page.Page),
+		Size:  int32(page.Size),
+		Total: page.Total,
+	}
+	for _, item := range page.Items {
+		resp.Items = append(resp.Items, &product.ProductHistoryEntry{
+			Id:        item.ID,
+			ProductId: uint64(item.ProductID),
+			Operation: item.Operation,
+			Before:    string(item.Before),
+			After:     string(item.After),
+			Actor:     item.Actor,
+			TraceId:   item.TraceID,
+			CreatedAt: timestamppb.New(item.CreatedAt),
+		})
+	}
+	return resp
+}
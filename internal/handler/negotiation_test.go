@@ -0,0 +1,31 @@
+package handler_test
+
+import (
+	"net/http"
+	"sample-crud/internal/config"
+	"testing"
+)
+
+func TestNegotiatedResponsesVaryOnAccept(t *testing.T) {
+	router := newRouter(newProductService(t, nil), config.TenantConfig{Default: "default"})
+	createProduct(t, router, nil)
+
+	cases := []struct {
+		name   string
+		path   string
+		accept string
+		status int
+	}{
+		{"list", "/api/v1/products", "application/x-msgpack", http.StatusOK},
+		{"error", "/api/v1/products/999", "application/x-protobuf", http.StatusNotFound},
+		{"default", "/api/v1/products/999", "", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := serve(router, http.MethodGet, tc.path, "", map[string]string{"Accept": tc.accept})
+			if recorder.Code != tc.status || recorder.Header().Get("Vary") != "Accept" {
+				t.Fatalf("expected %d varying on Accept, got %d %v", tc.status, recorder.Code, recorder.Header())
+			}
+		})
+	}
+}
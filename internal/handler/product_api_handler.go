@@ -9,6 +9,7 @@ import (
 	customerrors "sample-crud/pkg/errors"
 	"sample-crud/pkg/response"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
@@ -28,7 +29,7 @@ func NewProductApiHandler(productService service.ProductService) *ProductApiHand
 func (h *ProductApiHandler) Create(c *gin.Context) {
 	l := logger.GetLogger(c.Request.Context())
 	var request domain.ProductCreation
-	if err := c.ShouldBindJSON(&request); err != nil {
+	if err := bindBody(c, &request); err != nil {
 		l.Warn("Invalid product creation request", zap.Error(err))
 		if errors.Is(err, io.EOF) || err.Error() == "EOF" {
 			c.Error(customerrors.NewBadRequestError("Request body empty", err.Error()))
@@ -43,7 +44,7 @@ func (h *ProductApiHandler) Create(c *gin.Context) {
 		return
 	}
 	l.SInfo("Product created with id %v", id)
-	c.JSON(http.StatusCreated, response.Created(id))
+	render(c, http.StatusCreated, response.Created(id))
 }
 
 func (h *ProductApiHandler) FindByID(c *gin.Context) {
@@ -54,13 +55,63 @@ func (h *ProductApiHandler) FindByID(c *gin.Context) {
 		c.Error(customerrors.NewBadRequestError("Invalid product id", err.Error()))
 		return
 	}
-	productInfo, err2 := h.productService.FindByID(c.Request.Context(), uint(id))
-	if err2 != nil {
-		c.Error(err2)
+	var productInfo *domain.ProductInfo
+	if asOf := c.Query("as_of"); asOf != "" {
+		at, parseErr := time.Parse(time.RFC3339, asOf)
+		if parseErr != nil {
+			l.Warn("Invalid as_of", zap.String("as_of", asOf), zap.Error(parseErr))
+			c.Error(customerrors.NewBadRequestError("Invalid as_of, expected an RFC3339 time", parseErr.Error()))
+			return
+		}
+		productInfo, err = h.productService.FindByIDAsOf(c.Request.Context(), uint(id), at)
+	} else {
+		if h.notModifiedFromCache(c, uint(id)) {
+			l.SInfo("Product not modified with id %v", id)
+			return
+		}
+		productInfo, err = h.productService.FindByID(c.Request.Context(), uint(id))
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if c.Query("as_of") == "" && productInfo.UpdatedAt != nil && checkNotModified(c, productInfo.ID, *productInfo.UpdatedAt) {
+		l.SInfo("Product not modified with id %v", id)
 		return
 	}
 	l.SInfo("Product found with data : %+v", productInfo)
-	c.JSON(http.StatusOK, response.Success(productInfo))
+	render(c, http.StatusOK, response.Success(productInfo))
+}
+
+// notModifiedFromCache answers a conditional request from the cached update
+// time of the product, without loading it.
+func (h *ProductApiHandler) notModifiedFromCache(c *gin.Context, id uint) bool {
+	if !isConditional(c.Request) {
+		return false
+	}
+	updatedAt, err := h.productService.FindCachedUpdatedAt(c.Request.Context(), id)
+	if err != nil {
+		logger.GetLogger(c.Request.Context()).Warn("Fail to read cached product update time", zap.Error(err))
+		return false
+	}
+	return updatedAt != nil && checkNotModified(c, id, *updatedAt)
+}
+
+func (h *ProductApiHandler) List(c *gin.Context) {
+	l := logger.GetLogger(c.Request.Context())
+	var query domain.ProductListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		l.Warn("Invalid product list request", zap.Error(err))
+		c.Error(customerrors.NewBadRequestError(err.Error(), err.Error()))
+		return
+	}
+	page, err := h.productService.ListProducts(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	l.SInfo("Products listed with %d items of %d", len(page.Items), page.Total)
+	render(c, http.StatusOK, response.Success(page))
 }
 
 func (h *ProductApiHandler) Update(c *gin.Context) {
@@ -73,7 +124,7 @@ func (h *ProductApiHandler) Update(c *gin.Context) {
 	}
 
 	var request domain.ProductUpdate
-	if err := c.ShouldBindJSON(&request); err != nil {
+	if err := bindBody(c, &request); err != nil {
 		l.Warn("Invalid product update request", zap.Error(err))
 		if errors.Is(err, io.EOF) || err.Error() == "EOF" {
 			c.Error(customerrors.NewBadRequestError("Request body empty", err.Error()))
@@ -90,7 +141,7 @@ func (h *ProductApiHandler) Update(c *gin.Context) {
 	}
 
 	l.SInfo("Product updated with id %v", id)
-	c.JSON(http.StatusOK, response.Success(nil))
+	render(c, http.StatusOK, response.Success(nil))
 }
 
 func (h *ProductApiHandler) Delete(c *gin.Context) {
@@ -107,5 +158,28 @@ func (h *ProductApiHandler) Delete(c *gin.Context) {
 		return
 	}
 	l.SInfo("Product deleted with id %v", id)
-	c.JSON(http.StatusOK, response.Success(nil))
+	render(c, http.StatusOK, response.Success(nil))
+}
+
+func (h *ProductApiHandler) History(c *gin.Context) {
+	l := logger.GetLogger(c.Request.Context())
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		l.Warn("Invalid product id", zap.String("id", c.Param("id")), zap.Error(err))
+		c.Error(customerrors.NewBadRequestError("Invalid product id", err.Error()))
+		return
+	}
+	var query domain.PageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		l.Warn("Invalid product history request", zap.Error(err))
+		c.Error(customerrors.NewBadRequestError(err.Error(), err.Error()))
+		return
+	}
+	page, err := h.productService.FindHistory(c.Request.Context(), uint(id), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	l.SInfo("Product history found with %d items of %d", len(page.Items), page.Total)
+	render(c, http.StatusOK, response.Success(page))
 }
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sample-crud/internal/domain"
+	customerrors "sample-crud/pkg/errors"
+	"sample-crud/pkg/response"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// BatchAction serves POST /products:<action>. Gin cannot route a literal
+// colon next to a static segment, so the action arrives as a parameter, which
+// also matches paths such as /productsbatchCreate: only those with the colon
+// are actions. Paths below /products/ never reach it.
+func (h *ProductApiHandler) BatchAction(c *gin.Context) {
+	action, found := strings.CutPrefix(c.Param("action"), ":")
+	if !found {
+		c.Error(customerrors.NewNotFoundError("Not found", c.Request.URL.Path))
+		return
+	}
+	switch action {
+	case "batchCreate":
+		h.BatchCreate(c)
+	case "batchUpsert":
+		h.BatchUpsert(c)
+	case "batchDelete":
+		h.BatchDelete(c)
+	default:
+		c.Error(customerrors.NewNotFoundError("Unknown product action", c.Param("action")))
+	}
+}
+
+func (h *ProductApiHandler) BatchCreate(c *gin.Context) {
+	l := logger.GetLogger(c.Request.Context())
+	var request domain.BatchCreateRequest
+	if !bindBatchRequest(c, &request) {
+		return
+	}
+	result, err := runBatch(request.Items, func(items []domain.ProductCreation) ([]domain.BatchItemResult, error) {
+		return h.productService.BatchCreateProducts(c.Request.Context(), items)
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	l.SInfo("Products created in batch with %d succeeded and %d failed", result.Succeeded, result.Failed)
+	render(c, batchStatus(result), response.Batch(result, result.Failed))
+}
+
+func (h *ProductApiHandler) BatchUpsert(c *gin.Context) {
+	l := logger.GetLogger(c.Request.Context())
+	var request domain.BatchUpsertRequest
+	if !bindBatchRequest(c, &request) {
+		return
+	}
+	result, err := runBatch(request.Items, func(items []domain.ProductUpsert) ([]domain.BatchItemResult, error) {
+		return h.productService.BatchUpsertProducts(c.Request.Context(), items)
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	l.SInfo("Products upserted in batch with %d succeeded and %d failed", result.Succeeded, result.Failed)
+	render(c, batchStatus(result), response.Batch(result, result.Failed))
+}
+
+func (h *ProductApiHandler) BatchDelete(c *gin.Context) {
+	l := logger.GetLogger(c.Request.Context())
+	var request domain.BatchDeleteRequest
+	if !bindBatchRequest(c, &request) {
+		return
+	}
+	items, err := h.productService.BatchDeleteProducts(c.Request.Context(), request.IDs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	result := domain.NewBatchResult(items)
+	l.SInfo("Products deleted in batch with %d succeeded and %d failed", result.Succeeded, result.Failed)
+	render(c, batchStatus(result), response.Batch(result, result.Failed))
+}
+
+func bindBatchRequest(c *gin.Context, request interface{}) bool {
+	if err := bindBody(c, request); err != nil {
+		logger.GetLogger(c.Request.Context()).Warn("Invalid product batch request", zap.Error(err))
+		if errors.Is(err, io.EOF) || err.Error() == "EOF" {
+			c.Error(customerrors.NewBadRequestError("Request body empty", err.Error()))
+			return false
+		}
+		c.Error(customerrors.NewBadRequestError(err.Error(), err.Error()))
+		return false
+	}
+	return true
+}
+
+// runBatch validates every item on its own so that an invalid item fails
+// alone, and hands the valid ones to fn.
+func runBatch[T any](items []T, fn func([]T) ([]domain.BatchItemResult, error)) (*domain.BatchResult, error) {
+	results := make([]domain.BatchItemResult, len(items))
+	valid := make([]T, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	for i, item := range items {
+		if err := binding.Validator.ValidateStruct(item); err != nil {
+			results[i] = domain.BatchItemResult{Index: i, ErrorCode: customerrors.CodeBadRequest, Error: err.Error()}
+			continue
+		}
+		valid = append(valid, item)
+		indexes = append(indexes, i)
+	}
+	if len(valid) > 0 {
+		processed, err := fn(valid)
+		if err != nil {
+			return nil, err
+		}
+		for j, result := range processed {
+			result.Index = indexes[j]
+			results[indexes[j]] = result
+		}
+	}
+	return domain.NewBatchResult(results), nil
+}
+
+func batchStatus(result *domain.BatchResult) int {
+	if result.Failed > 0 {
+		return http.StatusMultiStatus
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sample-crud/internal/config"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/repo"
+	customerrors "sample-crud/pkg/errors"
+	"sample-crud/pkg/response"
+	"strings"
+	"testing"
+)
+
+// sharedSKURepository gives every product created in batch the same sku, so
+// that a batch breaks the sku uniqueness from its second item on.
+type sharedSKURepository struct {
+	repo.ProductRepository
+}
+
+func (r sharedSKURepository) CreateBatch(ctx context.Context, products []*domain.Product) []repo.BatchResult {
+	for _, product := range products {
+		sku := "SHARED"
+		product.SKU = &sku
+	}
+	return r.ProductRepository.CreateBatch(ctx, products)
+}
+
+func TestBatchCreateReportsDuplicateSKUPerItem(t *testing.T) {
+	productService := newProductService(t, func(r repo.ProductRepository) repo.ProductRepository {
+		return sharedSKURepository{r}
+	})
+	router := newRouter(productService, config.TenantConfig{Default: "default"})
+
+	recorder := serve(router, http.MethodPost, "/api/v1/products:batchCreate", `{"items":[{"name":"Keyboard"},{"name":"Mouse"}]}`, nil)
+
+	if recorder.Code != http.StatusMultiStatus {
+		t.Fatalf("expected 207, got %d: %s", recorder.Code, recorder.Body)
+	}
+	var body struct {
+		response.BaseResponse
+		Data domain.BatchResult `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.ResponseCode != response.CodePartialSuccess || body.Data.Succeeded != 1 || body.Data.Failed != 1 {
+		t.Fatalf("expected one item created and one failed, got %s", recorder.Body)
+	}
+	if item := body.Data.Items[1]; item.Success || item.ErrorCode != customerrors.CodeBadRequest {
+		t.Fatalf("expected the duplicate sku to fail with %s, got %+v", customerrors.CodeBadRequest, item)
+	}
+}
+
+// Gin routes /products:<action> through a parameter next to the static
+// /products, each path must still reach only its own handler.
+func TestBatchActionAndProductRoutesReachOnlyTheirHandler(t *testing.T) {
+	router := newRouter(newProductService(t, nil), config.TenantConfig{Default: "default"})
+	path := createProduct(t, router, nil)
+
+	recorder := serve(router, http.MethodPost, "/api/v1/products:batchCreate", `{"items":[{"name":"Mouse"}]}`, nil)
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"succeeded":1`) {
+		t.Fatalf("expected the batch create, got %d: %s", recorder.Code, recorder.Body)
+	}
+	recorder = serve(router, http.MethodGet, path, "", nil)
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"name":"Keyboard"`) {
+		t.Fatalf("expected the product, got %d: %s", recorder.Code, recorder.Body)
+	}
+	for _, request := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/productsbatchCreate"},
+		{http.MethodPost, "/api/v1/products:unknown"},
+		{http.MethodPost, "/api/v1/products:batchCreate/1"},
+		{http.MethodPost, path},
+		{http.MethodGet, "/api/v1/products:batchCreate"},
+		{http.MethodPut, "/api/v1/products:batchCreate"},
+	} {
+		recorder := serve(router, request.method, request.path, `{"items":[{"name":"Screen"}],"name":"Screen"}`, nil)
+		if recorder.Code != http.StatusNotFound {
+			t.Fatalf("expected %s %s to be not found, got %d: %s", request.method, request.path, recorder.Code, recorder.Body)
+		}
+	}
+	recorder = serve(router, http.MethodGet, "/api/v1/products", "", nil)
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"total":2`) {
+		t.Fatalf("expected only the two products created, got %d: %s", recorder.Code, recorder.Body)
+	}
+}
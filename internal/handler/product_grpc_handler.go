@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"errors"
+	"sample-crud/internal/domain"
 	"sample-crud/internal/service"
 	customerrors "sample-crud/pkg/errors"
 	"sample-crud/proto/pb/product"
@@ -17,21 +18,48 @@ type ProductGRPCHandler struct {
 }
 
 func (p ProductGRPCHandler) GetProduct(ctx context.Context, request *product.GetProductRequest) (*product.GetProductResponse, error) {
-	productInfo, err := p.productService.FindByID(ctx, uint(request.GetId()))
-	if err != nil {
-		var customErr *customerrors.CustomError
-		switch {
-		case errors.As(err, &customErr):
-			return nil, status.Error(customErr.GrpcCode, customErr.Message)
-		default:
-			return nil, status.Error(codes.Internal, "internal server error")
+	var productInfo *domain.ProductInfo
+	var err error
+	if request.GetAsOf() != nil {
+		if err := request.GetAsOf().CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid as_of: "+err.Error())
 		}
+		productInfo, err = p.productService.FindByIDAsOf(ctx, uint(request.GetId()), request.GetAsOf().AsTime())
+	} else {
+		productInfo, err = p.productService.FindByID(ctx, uint(request.GetId()))
+	}
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toProtoProduct(productInfo), nil
+}
+
+func (p ProductGRPCHandler) ListProductHistory(ctx context.Context, request *product.ListProductHistoryRequest) (*product.ListProductHistoryResponse, error) {
+	query := domain.PageQuery{Page: int(request.GetPage()), Size: int(request.GetSize())}
+	if query.Page == 0 {
+		query.Page = 1
 	}
-	resp := &product.GetProductResponse{
-		Id:   uint64(productInfo.ID),
-		Name: productInfo.Name,
+	if query.Size == 0 {
+		query.Size = 20
+	}
+	if query.Page < 1 || query.Size < 1 || query.Size > 100 {
+		return nil, status.Error(codes.InvalidArgument, "page must be at least 1 and size between 1 and 100")
+	}
+	page, err := p.productService.FindHistory(ctx, uint(request.GetId()), query)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return toProtoHistory(page), nil
+}
+
+func toGRPCError(err error) error {
+	var customErr *customerrors.CustomError
+	switch {
+	case errors.As(err, &customErr):
+		return status.Error(customErr.GrpcCode, customErr.Message)
+	default:
+		return status.Error(codes.Internal, "internal server error")
 	}
-	return resp, nil
 }
 
 func NewProductGRPCHandler(productService service.ProductService) *ProductGRPCHandler {
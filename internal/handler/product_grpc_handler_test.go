@@ -0,0 +1,76 @@
+package handler_test
+
+import (
+	"context"
+	"net"
+	"sample-crud/internal/handler"
+	"sample-crud/internal/interceptor"
+	"sample-crud/internal/service"
+	"sample-crud/internal/tenant"
+	"sample-crud/proto/pb/product"
+	"strconv"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient serves productService over an in-memory listener behind the
+// tenant interceptor.
+func newGRPCClient(t *testing.T, productService service.ProductService) product.ProductServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(interceptor.TenantUnaryInterceptor(tenant.NewResolver(tenantConfig))))
+	product.RegisterProductServiceServer(server, handler.NewProductGRPCHandler(productService))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return product.NewProductServiceClient(conn)
+}
+
+func TestGRPCTenantCannotReachAnotherTenantsProducts(t *testing.T) {
+	productService := newProductService(t, nil)
+	path := createProduct(t, newRouter(productService, tenantConfig), bearer(t, "tenant-a"))
+	id, err := strconv.ParseUint(path[strings.LastIndex(path, "/")+1:], 10, 64)
+	if err != nil {
+		t.Fatalf("parse id: %v", err)
+	}
+	client := newGRPCClient(t, productService)
+
+	cases := []struct {
+		name     string
+		metadata []string
+		code     codes.Code
+	}{
+		{"owner", []string{"authorization", bearer(t, "tenant-a")["Authorization"]}, codes.OK},
+		{"another tenant", []string{"authorization", bearer(t, "tenant-b")["Authorization"]}, codes.NotFound},
+		{"metadata only", []string{"x-tenant-id", "tenant-a"}, codes.Unauthenticated},
+		{"metadata contradicts", []string{"x-tenant-id", "tenant-a", "authorization", bearer(t, "tenant-b")["Authorization"]}, codes.Unauthenticated},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), tc.metadata...)
+			_, err := client.GetProduct(ctx, &product.GetProductRequest{Id: id})
+			if got := status.Code(err); got != tc.code {
+				t.Fatalf("expected %s, got %s: %v", tc.code, got, err)
+			}
+			_, err = client.ListProductHistory(ctx, &product.ListProductHistoryRequest{Id: id})
+			if tc.code == codes.Unauthenticated && status.Code(err) != codes.Unauthenticated {
+				t.Fatalf("expected history to be refused, got %v", err)
+			}
+		})
+	}
+}
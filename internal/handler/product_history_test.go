@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sample-crud/internal/config"
+	"sample-crud/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// History must read the same whatever the time zone of the server, which can
+// change between writing and reading it.
+func TestAsOfReadDoesNotDependOnTheServerTimeZone(t *testing.T) {
+	local := time.Local
+	t.Cleanup(func() { time.Local = local })
+	router := newRouter(newProductService(t, nil), config.TenantConfig{Default: "default"})
+
+	time.Local = time.FixedZone("UTC+7", 7*60*60)
+	path := createProduct(t, router, nil)
+	time.Sleep(10 * time.Millisecond)
+	beforeUpdate := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	if recorder := serve(router, http.MethodPut, path, `{"name":"Mouse"}`, nil); recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200 updating, got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	time.Local = time.FixedZone("UTC-5", -5*60*60)
+	for _, zone := range []*time.Location{time.UTC, time.Local, time.FixedZone("UTC+7", 7*60*60)} {
+		asOf := url.QueryEscape(beforeUpdate.In(zone).Format(time.RFC3339Nano))
+		recorder := serve(router, http.MethodGet, path+"?as_of="+asOf, "", nil)
+		var body struct {
+			Data struct {
+				Name string `json:"name"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if recorder.Code != http.StatusOK || body.Data.Name != "Keyboard" {
+			t.Fatalf("expected the product before its update as of %s, got %d: %s", asOf, recorder.Code, recorder.Body)
+		}
+	}
+}
+
+// historyActor returns the actor of the last write to the product at path.
+func historyActor(t *testing.T, router *gin.Engine, path string, headers map[string]string) string {
+	t.Helper()
+	recorder := serve(router, http.MethodGet, path+"/history", "", headers)
+	var body struct {
+		Data domain.ProductHistoryPage `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if recorder.Code != http.StatusOK || len(body.Data.Items) == 0 {
+		t.Fatalf("expected the history, got %d: %s", recorder.Code, recorder.Body)
+	}
+	return body.Data.Items[0].Actor
+}
+
+func TestActorIsTheSubjectOfAVerifiedToken(t *testing.T) {
+	router := newRouter(newProductService(t, nil), tenantConfig)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"tenant_id": "tenant-a", "sub": "alice"}).SignedString([]byte(tenantConfig.JWTSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	headers := map[string]string{"Authorization": "Bearer " + token, "X-Actor-ID": "mallory"}
+
+	path := createProduct(t, router, headers)
+
+	if actor := historyActor(t, router, path, headers); actor != "alice" {
+		t.Fatalf("expected the token subject as actor, got %q", actor)
+	}
+}
+
+func TestActorIsTheHeaderWithoutAToken(t *testing.T) {
+	router := newRouter(newProductService(t, nil), config.TenantConfig{Default: "default"})
+	headers := map[string]string{"X-Actor-ID": "bob"}
+
+	path := createProduct(t, router, headers)
+
+	if actor := historyActor(t, router, path, headers); actor != "bob" {
+		t.Fatalf("expected the header as actor, got %q", actor)
+	}
+}
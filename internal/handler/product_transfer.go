@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sample-crud/internal/domain"
+	customerrors "sample-crud/pkg/errors"
+	"sample-crud/pkg/response"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	maxImportSize   = 10 << 20
+	maxImportRows   = 10000
+	importChunkSize = 1000
+	// maxImportErrors rejected rows are listed in the response, all of them in
+	// the report.
+	maxImportErrors = 100
+)
+
+var (
+	exportHeader = []string{"id", "sku", "name"}
+	reportHeader = []string{"row", "sku", "name", "error_code", "error"}
+)
+
+func (h *ProductApiHandler) Export(c *gin.Context) {
+	l := logger.GetLogger(c.Request.Context())
+	format, err := spreadsheetFormat(c.Query("format"), "")
+	if err != nil {
+		c.Error(customerrors.NewBadRequestError(fmt.Sprintf("Invalid format, %v", err), err.Error()))
+		return
+	}
+	var writer rowWriter
+	start := func() error {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"products.%s\"", format))
+		c.Header("Content-Type", spreadsheetContentType(format))
+		c.Status(http.StatusOK)
+		var err error
+		if writer, err = newRowWriter(format, c.Writer); err != nil {
+			return err
+		}
+		return writer.Write(exportHeader)
+	}
+	exported := 0
+	err = h.productService.ExportProducts(c.Request.Context(), c.Query("q"), func(products []domain.ProductInfo) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, product := range products {
+			if err := writer.Write([]string{strconv.FormatUint(uint64(product.ID), 10), product.SKU, product.Name}); err != nil {
+				return err
+			}
+		}
+		exported += len(products)
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err == nil && writer == nil {
+		err = start()
+	}
+	switch {
+	case err != nil && writer == nil:
+		c.Error(err)
+	case err != nil:
+		// Part of the file is already sent, only the connection can tell.
+		l.Error("Fail to export products", zap.Int("exported", exported), zap.Error(err))
+		c.Abort()
+	default:
+		if err := writer.Close(); err != nil {
+			l.Error("Fail to write product export", zap.Error(err))
+			return
+		}
+		l.SInfo("Products exported with %d items as %s", exported, format)
+	}
+}
+
+// Import creates the rows of a csv or xlsx upload without sku and upserts the
+// others. Columns are found by their header, name and sku unless remapped with
+// columns[name] and columns[sku] form fields.
+func (h *ProductApiHandler) Import(c *gin.Context) {
+	l := logger.GetLogger(c.Request.Context())
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	upload, err := c.FormFile("file")
+	if err != nil {
+		l.Warn("Invalid product import request", zap.Error(err))
+		c.Error(customerrors.NewBadRequestError("Missing import file", err.Error()))
+		return
+	}
+	format, err := spreadsheetFormat(c.PostForm("format"), upload.Filename)
+	if err != nil {
+		c.Error(customerrors.NewBadRequestError(fmt.Sprintf("Invalid format, %v", err), err.Error()))
+		return
+	}
+	file, err := upload.Open()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer file.Close()
+	records, err := readRows(format, file)
+	if err != nil {
+		l.Warn("Invalid product import file", zap.Error(err))
+		c.Error(customerrors.NewBadRequestError(fmt.Sprintf("Invalid import file, %v", err), err.Error()))
+		return
+	}
+	rows, err := mapImportRows(records, c.PostFormMap("columns"))
+	if err != nil {
+		l.Warn("Invalid product import file", zap.Error(err))
+		c.Error(customerrors.NewBadRequestError(fmt.Sprintf("Invalid import file, %v", err), err.Error()))
+		return
+	}
+
+	rejected, err := h.importRows(c.Request.Context(), rows)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	result := &domain.ProductImportResult{
+		Total:     len(rows),
+		Succeeded: len(rows) - len(rejected),
+		Failed:    len(rejected),
+		Errors:    rejected[:min(len(rejected), maxImportErrors)],
+	}
+	if len(rejected) > 0 {
+		// Without the report, typically while Redis is unavailable, the
+		// response lists every rejected row instead.
+		if result.ReportID, err = h.productService.SaveImportReport(c.Request.Context(), rejected); err != nil {
+			result.Errors = rejected
+		}
+	}
+	l.SInfo("Products imported with %d succeeded and %d failed", result.Succeeded, result.Failed)
+	status := http.StatusOK
+	if result.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	render(c, status, response.Batch(result, result.Failed))
+}
+
+// importRows runs the rows through the batch operations in chunks, each in its
+// own transaction, and returns the rejected ones ordered by row.
+func (h *ProductApiHandler) importRows(ctx context.Context, rows []domain.ProductImportRow) ([]domain.ProductImportError, error) {
+	var creations []domain.ProductCreation
+	var upserts []domain.ProductUpsert
+	var creationRows, upsertRows []domain.ProductImportRow
+	for _, row := range rows {
+		if row.SKU == "" {
+			creations = append(creations, domain.ProductCreation{Name: row.Name})
+			creationRows = append(creationRows, row)
+		} else {
+			upserts = append(upserts, domain.ProductUpsert{SKU: row.SKU, Name: row.Name})
+			upsertRows = append(upsertRows, row)
+		}
+	}
+	rejected := make([]domain.ProductImportError, 0)
+	collect := func(rows []domain.ProductImportRow, result *domain.BatchResult) {
+		for _, item := range result.Items {
+			if !item.Success {
+				row := rows[item.Index]
+				rejected = append(rejected, domain.ProductImportError{Row: row.Row, SKU: row.SKU, Name: row.Name, ErrorCode: item.ErrorCode, Error: item.Error})
+			}
+		}
+	}
+	for start := 0; start < len(creations); start += importChunkSize {
+		end := min(start+importChunkSize, len(creations))
+		result, err := runBatch(creations[start:end], func(items []domain.ProductCreation) ([]domain.BatchItemResult, error) {
+			return h.productService.BatchCreateProducts(ctx, items)
+		})
+		if err != nil {
+			return nil, err
+		}
+		collect(creationRows[start:end], result)
+	}
+	for start := 0; start < len(upserts); start += importChunkSize {
+		end := min(start+importChunkSize, len(upserts))
+		result, err := runBatch(upserts[start:end], func(items []domain.ProductUpsert) ([]domain.BatchItemResult, error) {
+			return h.productService.BatchUpsertProducts(ctx, items)
+		})
+		if err != nil {
+			return nil, err
+		}
+		collect(upsertRows[start:end], result)
+	}
+	sort.Slice(rejected, func(i, j int) bool { return rejected[i].Row < rejected[j].Row })
+	return rejected, nil
+}
+
+// mapImportRows turns records into rows, skipping blank lines. The first record
+// is the header, matched case insensitively.
+func mapImportRows(records [][]string, columns map[string]string) ([]domain.ProductImportRow, error) {
+	if len(records) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	nameColumn, skuColumn := "name", "sku"
+	for field, column := range columns {
+		switch field {
+		case "name":
+			nameColumn = column
+		case "sku":
+			skuColumn = column
+		default:
+			return nil, fmt.Errorf("unknown field %q in column mapping, expected name or sku", field)
+		}
+	}
+	indexes := make(map[string]int, len(records[0]))
+	for i, header := range records[0] {
+		header = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		if _, ok := indexes[header]; !ok {
+			indexes[header] = i
+		}
+	}
+	nameIndex, ok := indexes[strings.ToLower(nameColumn)]
+	if !ok {
+		return nil, fmt.Errorf("name column %q not found", nameColumn)
+	}
+	skuIndex, ok := indexes[strings.ToLower(skuColumn)]
+	if !ok {
+		if _, mapped := columns["sku"]; mapped {
+			return nil, fmt.Errorf("sku column %q not found", skuColumn)
+		}
+		skuIndex = -1
+	}
+	rows := make([]domain.ProductImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("file has more than %d rows", maxImportRows)
+		}
+		rows = append(rows, domain.ProductImportRow{Row: i + 2, SKU: cell(record, skuIndex), Name: cell(record, nameIndex)})
+	}
+	return rows, nil
+}
+
+func cell(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return unescapeFormula(strings.TrimSpace(record[index]))
+}
+
+func (h *ProductApiHandler) ImportReport(c *gin.Context) {
+	l := logger.GetLogger(c.Request.Context())
+	format, err := spreadsheetFormat(c.Query("format"), "")
+	if err != nil {
+		c.Error(customerrors.NewBadRequestError(fmt.Sprintf("Invalid format, %v", err), err.Error()))
+		return
+	}
+	reportID := c.Param("report_id")
+	rejected, err := h.productService.FindImportReport(c.Request.Context(), reportID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"product-import-%s.%s\"", reportID, format))
+	c.Header("Content-Type", spreadsheetContentType(format))
+	c.Status(http.StatusOK)
+	writer, err := newRowWriter(format, c.Writer)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := writer.Write(reportHeader); err != nil {
+		l.Error("Fail to write product import report", zap.Error(err))
+		return
+	}
+	for _, row := range rejected {
+		if err := writer.Write([]string{strconv.Itoa(row.Row), row.SKU, row.Name, row.ErrorCode, row.Error}); err != nil {
+			l.Error("Fail to write product import report", zap.Error(err))
+			return
+		}
+	}
+	if err := writer.Close(); err != nil {
+		l.Error("Fail to write product import report", zap.Error(err))
+		return
+	}
+	l.SInfo("Product import report %s downloaded with %d rows", reportID, len(rejected))
+}
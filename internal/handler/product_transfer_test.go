@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sample-crud/infra/cache"
+	"sample-crud/internal/config"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/requestctx"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// slowValues is a Redis hook that takes a millisecond for every 4 KB a command
+// writes or reads, like a busy server moving large values would.
+type slowValues struct{}
+
+func (slowValues) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (slowValues) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		size := 0
+		for _, arg := range cmd.Args() {
+			if value, ok := arg.([]byte); ok {
+				size += len(value)
+			}
+		}
+		if err := next(ctx, cmd); err != nil {
+			return err
+		}
+		if reply, ok := cmd.(*redis.StringCmd); ok {
+			size += len(reply.Val())
+		}
+		select {
+		case <-time.After(time.Duration(size/4096) * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			cmd.SetErr(ctx.Err())
+			return ctx.Err()
+		}
+	}
+}
+
+func (slowValues) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestImportReportNearTheRowLimitDoesNotOpenTheBreaker(t *testing.T) {
+	host, port, err := net.SplitHostPort(miniredis.RunT(t).Addr())
+	if err != nil {
+		t.Fatalf("split address: %v", err)
+	}
+	redisClient := cache.NewRedisClient(config.RedisConfig{
+		Host:             host,
+		Port:             port,
+		CallTimeout:      50 * time.Millisecond,
+		BreakerThreshold: 1,
+		ReconnectEvery:   time.Minute,
+	})
+	t.Cleanup(cache.Close)
+	redisClient.AddHook(slowValues{})
+	productService := newProductServiceOn(t, redisClient, nil)
+	ctx := requestctx.WithTenant(context.Background(), "default")
+	rejected := make([]domain.ProductImportError, 9999)
+	for i := range rejected {
+		rejected[i] = domain.ProductImportError{
+			Row:       i + 2,
+			SKU:       fmt.Sprintf("SKU-%05d", i),
+			Name:      strings.Repeat("n", 40),
+			ErrorCode: "VALIDATION_ERROR",
+			Error:     "name is too long",
+		}
+	}
+
+	reportID, err := productService.SaveImportReport(ctx, rejected)
+	if err != nil {
+		t.Fatalf("save report: %v", err)
+	}
+	report, err := productService.FindImportReport(ctx, reportID)
+
+	if err != nil || len(report) != len(rejected) {
+		t.Fatalf("expected the %d rejected rows back, got %d, %v", len(rejected), len(report), err)
+	}
+	if !cache.Available() {
+		t.Fatal("expected the breaker to stay closed")
+	}
+}
@@ -0,0 +1,77 @@
+package handler_test
+
+import (
+	"net/http/httptest"
+	"sample-crud/internal/config"
+	"sample-crud/internal/handler"
+	"sample-crud/internal/middleware"
+	"sample-crud/internal/repo"
+	"sample-crud/internal/repo/repotest"
+	"sample-crud/internal/service"
+	"sample-crud/internal/tenant"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// newProductService runs the service on a private SQLite database and Redis,
+// its products stored by the repository wrap returns, nil keeping the GORM
+// one.
+func newProductService(t *testing.T, wrap func(repo.ProductRepository) repo.ProductRepository) service.ProductService {
+	t.Helper()
+	redisClient := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+	return newProductServiceOn(t, redisClient, wrap)
+}
+
+// newProductServiceOn is newProductService on the given Redis.
+func newProductServiceOn(t *testing.T, redisClient redis.UniversalClient, wrap func(repo.ProductRepository) repo.ProductRepository) service.ProductService {
+	t.Helper()
+	resolver := repotest.OpenSQLite(t)
+	var productRepo repo.ProductRepository = repo.NewGormProductRepository(resolver)
+	if wrap != nil {
+		productRepo = wrap(productRepo)
+	}
+	return service.NewProductService(
+		productRepo,
+		repo.NewGormOutboxRepository(resolver),
+		repo.NewGormProductHistoryRepository(resolver),
+		repo.NewGormTransactionManager(resolver, repo.ParseIsolationLevel(""), 0),
+		redisClient,
+	)
+}
+
+// newRouter serves the product routes like the application does, minus
+// logging, idempotency and validation.
+func newRouter(productService service.ProductService, tenantCfg config.TenantConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorRecover())
+	router.Use(middleware.Actor())
+	productHandler := handler.NewProductApiHandler(productService)
+	v1 := router.Group("/api/v1", middleware.Tenant(tenant.NewResolver(tenantCfg)))
+	{
+		v1.POST("/products", productHandler.Create)
+		v1.GET("/products", productHandler.List)
+		v1.GET("/products/:id", productHandler.FindByID)
+		v1.PUT("/products/:id", productHandler.Update)
+		v1.DELETE("/products/:id", productHandler.Delete)
+		v1.GET("/products/:id/history", productHandler.History)
+		v1.POST("/products:action", productHandler.BatchAction)
+	}
+	return router
+}
+
+func serve(router *gin.Engine, method string, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+
+	contentTypeCSV  = "text/csv; charset=utf-8"
+	contentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	sheetName = "Sheet1"
+)
+
+// spreadsheetFormat takes the explicit format, or else the extension of
+// filename, csv by default.
+func spreadsheetFormat(format string, filename string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	}
+	switch format {
+	case "", formatCSV:
+		return formatCSV, nil
+	case formatXLSX:
+		return formatXLSX, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, expected csv or xlsx", format)
+	}
+}
+
+func spreadsheetContentType(format string) string {
+	if format == formatXLSX {
+		return contentTypeXLSX
+	}
+	return contentTypeCSV
+}
+
+// rowWriter writes rows of cells, Flush sending those written so far to the
+// underlying writer. Cells a spreadsheet application would take for a formula
+// are escaped, see escapeFormula.
+type rowWriter interface {
+	Write(cells []string) error
+	Flush() error
+	Close() error
+}
+
+func newRowWriter(format string, w io.Writer) (rowWriter, error) {
+	if format == formatXLSX {
+		return newXLSXRowWriter(w)
+	}
+	return &csvRowWriter{w: csv.NewWriter(w)}, nil
+}
+
+// formulaPrefixes start the cells spreadsheet applications evaluate.
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula prefixes with a quote the values a spreadsheet application
+// would evaluate as a formula.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeFormula undoes escapeFormula, for exported files to import as they
+// were exported.
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRowWriter) Write(cells []string) error {
+	escaped := make([]string, len(cells))
+	for i, value := range cells {
+		escaped[i] = escapeFormula(value)
+	}
+	return c.w.Write(escaped)
+}
+
+func (c *csvRowWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvRowWriter) Close() error {
+	return c.Flush()
+}
+
+// xlsxParts are the parts of a workbook holding a single sheet, written
+// before its rows.
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + sheetName + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxRowWriter streams the sheet of an xlsx archive as rows are written, its
+// cells being inline strings, instead of building the workbook in memory.
+type xlsxRowWriter struct {
+	zip   *zip.Writer
+	flate *flate.Writer
+	sheet io.Writer
+	row   int
+}
+
+func newXLSXRowWriter(w io.Writer) (*xlsxRowWriter, error) {
+	x := &xlsxRowWriter{zip: zip.NewWriter(w)}
+	// Keep the compressor of the sheet, to flush it along with the archive.
+	x.zip.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		var err error
+		x.flate, err = flate.NewWriter(out, flate.DefaultCompression)
+		return x.flate, err
+	})
+	for _, part := range xlsxParts {
+		file, err := x.zip.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if x.sheet, err = x.zip.Create("xl/worksheets/sheet1.xml"); err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(x.sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return x, err
+}
+
+func (x *xlsxRowWriter) Write(cells []string) error {
+	x.row++
+	var row strings.Builder
+	fmt.Fprintf(&row, `<row r="%d">`, x.row)
+	for i, value := range cells {
+		name, err := excelize.CoordinatesToCellName(i+1, x.row)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&row, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, name)
+		if err := xml.EscapeText(&row, []byte(escapeFormula(value))); err != nil {
+			return err
+		}
+		row.WriteString(`</t></is></c>`)
+	}
+	row.WriteString(`</row>`)
+	_, err := io.WriteString(x.sheet, row.String())
+	return err
+}
+
+func (x *xlsxRowWriter) Flush() error {
+	if err := x.flate.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Flush()
+}
+
+func (x *xlsxRowWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// readRows reads every row of a csv file, or of the first sheet of an xlsx
+// one.
+func readRows(format string, r io.Reader) ([][]string, error) {
+	if format == formatXLSX {
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, nil
+		}
+		return file.GetRows(sheets[0])
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader.ReadAll()
+}
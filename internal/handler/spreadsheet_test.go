@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSpreadsheetRoundTripEscapesFormulas(t *testing.T) {
+	rows := [][]string{
+		{"id", "sku", "name"},
+		{"1", "-SKU", "=HYPERLINK(\"http://example.com\")"},
+		{"2", "@sku", "+1 <cable> & plug"},
+		{"3", "", "Plain"},
+	}
+	for _, format := range []string{formatCSV, formatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var file bytes.Buffer
+			writer, err := newRowWriter(format, &file)
+			if err != nil {
+				t.Fatalf("newRowWriter: %v", err)
+			}
+			for _, row := range rows {
+				sent := file.Len()
+				if err := writer.Write(row); err != nil {
+					t.Fatalf("Write: %v", err)
+				}
+				if err := writer.Flush(); err != nil {
+					t.Fatalf("Flush: %v", err)
+				}
+				if file.Len() == sent {
+					t.Fatalf("expected the row sent on Flush")
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			read, err := readRows(format, &file)
+			if err != nil {
+				t.Fatalf("readRows: %v", err)
+			}
+			if read[1][2] != "'=HYPERLINK(\"http://example.com\")" || read[2][1] != "'@sku" {
+				t.Fatalf("expected formulas escaped, got %q", read)
+			}
+			for i, record := range read {
+				unescaped := make([]string, len(rows[i]))
+				for j := range unescaped {
+					unescaped[j] = cell(record, j)
+				}
+				if !reflect.DeepEqual(unescaped, rows[i]) {
+					t.Fatalf("expected row %d to import as %q, got %q", i, rows[i], unescaped)
+				}
+			}
+		})
+	}
+}
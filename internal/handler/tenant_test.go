@@ -0,0 +1,98 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sample-crud/internal/config"
+	"sample-crud/pkg/response"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var tenantConfig = config.TenantConfig{Required: true, JWTSecret: "test-secret", JWTClaim: "tenant_id"}
+
+// bearer signs a token for tenantID with the test secret.
+func bearer(t *testing.T, tenantID string) map[string]string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"tenant_id": tenantID}).SignedString([]byte(tenantConfig.JWTSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// createProduct creates a product with headers and returns its path.
+func createProduct(t *testing.T, router *gin.Engine, headers map[string]string) string {
+	t.Helper()
+	recorder := serve(router, http.MethodPost, "/api/v1/products", `{"name":"Keyboard"}`, headers)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", recorder.Code, recorder.Body)
+	}
+	var body struct {
+		Data response.CreatedData `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return fmt.Sprintf("/api/v1/products/%d", body.Data.ID)
+}
+
+func TestTenantCannotReachAnotherTenantsProducts(t *testing.T) {
+	router := newRouter(newProductService(t, nil), tenantConfig)
+	path := createProduct(t, router, bearer(t, "tenant-a"))
+	tenantB := bearer(t, "tenant-b")
+
+	if recorder := serve(router, http.MethodGet, path, "", tenantB); recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 reading as another tenant, got %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve(router, http.MethodPut, path, `{"name":"Mouse"}`, tenantB); recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 updating as another tenant, got %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve(router, http.MethodDelete, path, "", tenantB); recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 deleting as another tenant, got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	recorder := serve(router, http.MethodGet, path, "", bearer(t, "tenant-a"))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200 reading as the owner, got %d: %s", recorder.Code, recorder.Body)
+	}
+	var body struct {
+		Data struct {
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Data.Name != "Keyboard" {
+		t.Fatalf("expected the product left untouched, got %s", recorder.Body)
+	}
+}
+
+func TestTenantHeaderAloneIsRejectedWhenTokensAreRequired(t *testing.T) {
+	router := newRouter(newProductService(t, nil), tenantConfig)
+	path := createProduct(t, router, bearer(t, "tenant-a"))
+
+	cases := map[string]map[string]string{
+		"header only":         {"X-Tenant-ID": "tenant-a"},
+		"header and no token": {"X-Tenant-ID": "tenant-a", "Authorization": "Basic dXNlcjpwYXNz"},
+		"header contradicts":  {"X-Tenant-ID": "tenant-a", "Authorization": bearer(t, "tenant-b")["Authorization"]},
+		"forged token":        {"Authorization": "Bearer not-a-token"},
+	}
+	for name, headers := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, method := range []string{http.MethodGet, http.MethodDelete} {
+				if recorder := serve(router, method, path, "", headers); recorder.Code != http.StatusUnauthorized {
+					t.Fatalf("expected 401 on %s, got %d: %s", method, recorder.Code, recorder.Body)
+				}
+			}
+		})
+	}
+
+	if recorder := serve(router, http.MethodGet, path, "", bearer(t, "tenant-a")); recorder.Code != http.StatusOK {
+		t.Fatalf("expected the product left in place, got %d: %s", recorder.Code, recorder.Body)
+	}
+}
@@ -0,0 +1,173 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sample-crud/infra/cache"
+	"sample-crud/internal/config"
+	customerrors "sample-crud/pkg/errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	Header = "Idempotency-Key"
+	// ReplayedHeader marks responses replayed from a previous request.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+// ErrClaimLost is returned when completing a key whose claim expired and may
+// have been taken by another request.
+var ErrClaimLost = errors.New("idempotency key claim lost")
+
+// The claim scripts act on a key only while it still holds the record of the
+// claim, which its token makes unique.
+var (
+	completeScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1`)
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])`)
+)
+
+// Response is the first response given to a key.
+type Response struct {
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body"`
+}
+
+// record is what is stored under a key, without a response while the first
+// request is in flight.
+type record struct {
+	Fingerprint string    `json:"fingerprint"`
+	Token       string    `json:"token,omitempty"`
+	Response    *Response `json:"response,omitempty"`
+}
+
+// Claim is a key claimed by Begin, until it is completed or released.
+type Claim struct {
+	redisKey    string
+	fingerprint string
+	// value is the record written by Begin.
+	value string
+}
+
+// Store keeps, for every key of a tenant, a fingerprint of the first request
+// and then its response. A key is claimed for lockTimeout while its request is
+// in flight, and its response kept for ttl.
+type Store struct {
+	client      redis.UniversalClient
+	ttl         time.Duration
+	lockTimeout time.Duration
+}
+
+// Begin claims key for the request with the given fingerprint. It returns the
+// stored response when the key was already used by the same request, or the
+// claim when the caller should handle the request and then Complete or
+// Release it.
+func (s Store) Begin(ctx context.Context, tenant string, key string, fingerprint string) (*Response, *Claim, error) {
+	if key == "" || len(key) > maxKeyLength {
+		return nil, nil, customerrors.NewBadRequestError("Invalid idempotency key", fmt.Sprintf("key must be 1 to %d characters", maxKeyLength))
+	}
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, nil, err
+	}
+	value, err := json.Marshal(record{Fingerprint: fingerprint, Token: hex.EncodeToString(token)})
+	if err != nil {
+		return nil, nil, err
+	}
+	redisKey := getIdempotencyKey(tenant, key)
+	claimed, err := s.client.SetNX(ctx, redisKey, value, s.lockTimeout).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	if claimed {
+		return nil, &Claim{redisKey: redisKey, fingerprint: fingerprint, value: string(value)}, nil
+	}
+	stored, err := s.client.Get(ctx, redisKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Released or expired in between, the next attempt claims it.
+		return nil, nil, customerrors.NewConflictError("Request in progress", "a request with this idempotency key is in progress")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var existing record
+	if err := json.Unmarshal(stored, &existing); err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case existing.Fingerprint != fingerprint:
+		return nil, nil, customerrors.NewUnprocessableError("Idempotency key reused", "the idempotency key was used for a different request")
+	case existing.Response == nil:
+		return nil, nil, customerrors.NewConflictError("Request in progress", "a request with this idempotency key is in progress")
+	}
+	return existing.Response, nil, nil
+}
+
+// Complete stores the response given to a claimed key, unless the claim
+// expired meanwhile.
+func (s Store) Complete(ctx context.Context, claim *Claim, response Response) error {
+	value, err := json.Marshal(record{Fingerprint: claim.fingerprint, Response: &response})
+	if err != nil {
+		return err
+	}
+	stored, err := completeScript.Run(ctx, s.client, []string{claim.redisKey}, claim.value, value, s.ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if stored == 0 {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// Release frees a claimed key whose request failed, so that it can be retried.
+// A key claimed by another request since the claim expired is left alone.
+func (s Store) Release(ctx context.Context, claim *Claim) error {
+	return releaseScript.Run(ctx, s.client, []string{claim.redisKey}, claim.value).Err()
+}
+
+// Available tells whether keys can be checked, requests being handled without
+// idempotency while Redis is down.
+func (s Store) Available() bool {
+	return cache.Available()
+}
+
+// Fingerprint hashes the parts identifying a request.
+func Fingerprint(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func getIdempotencyKey(tenant string, key string) string {
+	return fmt.Sprintf("%s:%s:idempotency#%s", cache.Namespace, tenant, key)
+}
+
+func NewStore(client redis.UniversalClient, cfg config.IdempotencyConfig) *Store {
+	return &Store{
+		client:      client,
+		ttl:         cfg.TTL,
+		lockTimeout: cfg.LockTimeout,
+	}
+}
@@ -0,0 +1,50 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sample-crud/internal/config"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// A request outliving its claim must leave the key to the request that
+// claimed it next.
+func TestExpiredClaimDoesNotTouchTheNextOne(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	store := NewStore(client, config.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute})
+	ctx := context.Background()
+
+	_, first, err := store.Begin(ctx, "default", "key-1", "fingerprint")
+	if err != nil || first == nil {
+		t.Fatalf("expected the key claimed, got %v, %v", first, err)
+	}
+	server.FastForward(2 * time.Minute)
+	_, second, err := store.Begin(ctx, "default", "key-1", "fingerprint")
+	if err != nil || second == nil {
+		t.Fatalf("expected the expired key claimed again, got %v, %v", second, err)
+	}
+
+	if err := store.Release(ctx, first); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if err := store.Complete(ctx, first, Response{Status: 200}); !errors.Is(err, ErrClaimLost) {
+		t.Fatalf("expected the expired claim lost, got %v", err)
+	}
+	if _, _, err := store.Begin(ctx, "default", "key-1", "fingerprint"); err == nil {
+		t.Fatal("expected the second claim to still hold the key")
+	}
+
+	if err := store.Complete(ctx, second, Response{Status: 200, Body: []byte("done")}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	stored, claim, err := store.Begin(ctx, "default", "key-1", "fingerprint")
+	if err != nil || claim != nil || stored == nil || string(stored.Body) != "done" {
+		t.Fatalf("expected the response of the second request replayed, got %+v, %v", stored, err)
+	}
+}
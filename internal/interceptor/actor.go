@@ -0,0 +1,21 @@
+package interceptor
+
+import (
+	"context"
+	"sample-crud/internal/requestctx"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func ActorUnaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if values := metadata.ValueFromIncomingContext(ctx, requestctx.ActorMetadata); len(values) > 0 && values[0] != "" {
+		ctx = requestctx.WithActor(ctx, values[0])
+	}
+	return handler(ctx, req)
+}
@@ -0,0 +1,19 @@
+package interceptor
+
+import (
+	"context"
+	"sample-crud/infra/db"
+
+	"google.golang.org/grpc"
+)
+
+// DatabaseScopeUnaryInterceptor pins the reads of a call to the primary once it
+// has written.
+func DatabaseScopeUnaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	return handler(db.WithRequestScope(ctx), req)
+}
@@ -0,0 +1,40 @@
+package interceptor
+
+import (
+	"context"
+	"sample-crud/internal/requestctx"
+	"sample-crud/internal/tenant"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TenantUnaryInterceptor scopes the call to its tenant. The subject of a
+// verified token becomes the actor, in place of the one given by
+// ActorUnaryInterceptor.
+func TenantUnaryInterceptor(resolver *tenant.Resolver) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		identity, err := resolver.Resolve(firstMetadata(ctx, requestctx.TenantMetadata), firstMetadata(ctx, "authorization"))
+		if err != nil {
+			return nil, status.Error(err.GrpcCode, err.Message)
+		}
+		ctx = requestctx.WithTenant(ctx, identity.Tenant)
+		if identity.Subject != "" {
+			ctx = requestctx.WithActor(ctx, identity.Subject)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"sample-crud/internal/requestctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Actor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if actor := c.GetHeader(requestctx.ActorHeader); actor != "" {
+			c.Request = c.Request.WithContext(requestctx.WithActor(c.Request.Context(), actor))
+		}
+		c.Next()
+	}
+}
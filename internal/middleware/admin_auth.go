@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	customerrors "sample-crud/pkg/errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.Error(customerrors.NewUnauthorizedError("Invalid admin token", "missing or invalid bearer token"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"sample-crud/infra/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DatabaseScope pins the reads of a request to the primary once it has written.
+func DatabaseScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(db.WithRequestScope(c.Request.Context()))
+		c.Next()
+	}
+}
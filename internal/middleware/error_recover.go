@@ -5,8 +5,10 @@ import (
 	"net/http"
 	customerrors "sample-crud/pkg/errors"
 	"sample-crud/pkg/response"
+	"sample-crud/proto/pb/product"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
 )
 
 func ErrorRecover() gin.HandlerFunc {
@@ -18,11 +20,17 @@ func ErrorRecover() gin.HandlerFunc {
 				var customErr *customerrors.CustomError
 				switch {
 				case errors.As(err, &customErr):
-					c.JSON(customErr.HttpStatus, response.Error(customErr.Code, customErr.Message))
+					renderError(c, customErr.HttpStatus, response.Error(customErr.Code, customErr.Message))
 				default:
-					c.JSON(http.StatusInternalServerError, response.InternalServerError())
+					renderError(c, http.StatusInternalServerError, response.InternalServerError())
 				}
 			}
 		}
 	}
 }
+
+func renderError(c *gin.Context, status int, body response.BaseResponse) {
+	response.Render(c, status, body, func() (proto.Message, error) {
+		return &product.BaseResponse{ResponseCode: body.ResponseCode, ResponseMessage: body.ResponseMessage}, nil
+	})
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sample-crud/internal/idempotency"
+	"sample-crud/internal/requestctx"
+	customerrors "sample-crud/pkg/errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+// maxIdempotentBodySize bounds the bodies read for their fingerprint, at the
+// limit of the largest route, the import.
+const maxIdempotentBodySize = 10 << 20
+
+// Idempotency replays the first response of a POST, PUT or PATCH to the
+// repeats of its Idempotency-Key. Failed requests are not remembered, so that a retry
+// handles them again, and requests are handled without it while Redis is down.
+func Idempotency(store *idempotency.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotency.Header)
+		method := c.Request.Method
+		if key == "" || (method != http.MethodPost && method != http.MethodPut && method != http.MethodPatch) || !store.Available() {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			c.Error(customerrors.NewCustomError(http.StatusRequestEntityTooLarge, codes.InvalidArgument, customerrors.CodeBadRequest, "Request body too large", err.Error()))
+			c.Abort()
+			return
+		case err != nil:
+			c.Error(customerrors.NewBadRequestError("Invalid request body", err.Error()))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		tenant := requestctx.Tenant(ctx)
+		fingerprint := idempotency.Fingerprint([]byte(method), []byte(c.Request.URL.RequestURI()), bodyFingerprint(c.ContentType(), c.GetHeader("Content-Type"), body))
+		stored, claim, err := store.Begin(ctx, tenant, key, fingerprint)
+		var customErr *customerrors.CustomError
+		switch {
+		case errors.As(err, &customErr):
+			c.Error(customErr)
+			c.Abort()
+			return
+		case err != nil:
+			logger.GetLogger(ctx).Warn("Fail to claim idempotency key, handling request without it", zap.Error(err))
+			c.Next()
+			return
+		case stored != nil:
+			c.Header(idempotency.ReplayedHeader, "true")
+			c.Header("Vary", "Accept")
+			c.Data(stored.Status, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		// The client may be gone, typically after timing out, and retry soon.
+		detached := context.WithoutCancel(ctx)
+		if len(c.Errors) > 0 || writer.Status() >= http.StatusInternalServerError {
+			err = store.Release(detached, claim)
+		} else {
+			err = store.Complete(detached, claim, idempotency.Response{
+				Status:      writer.Status(),
+				ContentType: writer.Header().Get("Content-Type"),
+				Body:        writer.body.Bytes(),
+			})
+		}
+		if err != nil {
+			logger.GetLogger(ctx).Warn("Fail to save idempotency key", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// bodyFingerprint returns what identifies body: itself, or for a multipart
+// body its parts without the boundary, which clients pick at random for every
+// attempt.
+func bodyFingerprint(mediaType string, contentType string, body []byte) []byte {
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return body
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["boundary"] == "" {
+		return body
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	hash := sha256.New()
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return hash.Sum([]byte(mediaType))
+		}
+		if err != nil {
+			return body
+		}
+		fmt.Fprintf(hash, "%q %q %q\n", part.FormName(), part.FileName(), part.Header.Get("Content-Type"))
+		if _, err := io.Copy(hash, part); err != nil {
+			return body
+		}
+		hash.Write([]byte{0})
+	}
+}
+
+// recordingWriter keeps a copy of the body written to the client.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,119 @@
+package middleware_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sample-crud/infra/cache"
+	"sample-crud/internal/config"
+	"sample-crud/internal/idempotency"
+	"sample-crud/internal/middleware"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func newIdempotencyStore(t *testing.T) *idempotency.Store {
+	t.Helper()
+	host, port, err := net.SplitHostPort(miniredis.RunT(t).Addr())
+	if err != nil {
+		t.Fatalf("split address: %v", err)
+	}
+	client := cache.NewRedisClient(config.RedisConfig{
+		Host:             host,
+		Port:             port,
+		CallTimeout:      time.Second,
+		BreakerThreshold: 5,
+		ReconnectEvery:   time.Second,
+	})
+	t.Cleanup(cache.Close)
+	return idempotency.NewStore(client, config.IdempotencyConfig{TTL: time.Hour, LockTimeout: time.Minute})
+}
+
+// newIdempotentRouter counts the requests reaching the handler.
+func newIdempotentRouter(t *testing.T, handled *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorRecover(), middleware.Idempotency(newIdempotencyStore(t)))
+	router.PUT("/products/1", func(c *gin.Context) {
+		*handled++
+		c.String(http.StatusOK, "updated")
+	})
+	router.POST("/import", func(c *gin.Context) {
+		*handled++
+		if _, err := c.FormFile("file"); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, "imported")
+	})
+	return router
+}
+
+func postUpload(router *gin.Engine, key string, content string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, _ := form.CreateFormFile("file", "catalog.csv")
+	_, _ = file.Write([]byte(content))
+	_ = form.WriteField("format", "csv")
+	_ = form.Close()
+	request := httptest.NewRequest(http.MethodPost, "/import", &body)
+	request.Header.Set("Content-Type", form.FormDataContentType())
+	request.Header.Set(idempotency.Header, key)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotentUploadIgnoresTheBoundary(t *testing.T) {
+	handled := 0
+	router := newIdempotentRouter(t, &handled)
+
+	first := postUpload(router, "upload-1", "name\nKeyboard\n")
+	retry := postUpload(router, "upload-1", "name\nKeyboard\n")
+
+	if first.Code != http.StatusOK || retry.Code != http.StatusOK || handled != 1 {
+		t.Fatalf("expected the retry replayed, got %d then %d with %d handled", first.Code, retry.Code, handled)
+	}
+	if retry.Header().Get(idempotency.ReplayedHeader) != "true" {
+		t.Fatalf("expected the replay header, got %v", retry.Header())
+	}
+	if other := postUpload(router, "upload-1", "name\nMouse\n"); other.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected another file under the same key to answer 422, got %d", other.Code)
+	}
+}
+
+func TestIdempotentBodyIsBounded(t *testing.T) {
+	handled := 0
+	router := newIdempotentRouter(t, &handled)
+
+	recorder := postUpload(router, "upload-2", strings.Repeat("x", 10<<20))
+
+	if recorder.Code != http.StatusRequestEntityTooLarge || handled != 0 {
+		t.Fatalf("expected 413 before the handler, got %d with %d handled", recorder.Code, handled)
+	}
+}
+
+func TestIdempotentUpdateIsHandledOnce(t *testing.T) {
+	handled := 0
+	router := newIdempotentRouter(t, &handled)
+
+	for i := 0; i < 2; i++ {
+		request := httptest.NewRequest(http.MethodPut, "/products/1", strings.NewReader(`{"name":"Mouse"}`))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set(idempotency.Header, "update-1")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", recorder.Code)
+		}
+	}
+	if handled != 1 {
+		t.Fatalf("expected the repeated update replayed, handled %d times", handled)
+	}
+}
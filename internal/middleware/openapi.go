@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	customerrors "sample-crud/pkg/errors"
+	"sample-crud/pkg/response"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// OpenAPIValidation rejects requests not matching the document and logs JSON
+// responses not matching it, routes it does not describe being left alone. It
+// buffers every response, so it is meant for debug mode, and must come before
+// ErrorRecover to see error responses.
+func OpenAPIValidation(doc *openapi3.T) (gin.HandlerFunc, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	options := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+	}
+	// Only JSON bodies are described, protobuf and msgpack ones are left to
+	// the handlers.
+	binaryOptions := *options
+	binaryOptions.ExcludeRequestBody = true
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+		l := logger.GetLogger(ctx)
+		request := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+		switch c.ContentType() {
+		case binding.MIMEPROTOBUF, binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+			request.Options = &binaryOptions
+		}
+		if err := openapi3filter.ValidateRequest(ctx, request); err != nil {
+			l.Warn("Request does not match the OpenAPI specification", zap.String("reason", validationMessage(err)))
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.Error(customerrors.CodeBadRequest, validationMessage(err)))
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		if !strings.HasPrefix(writer.Header().Get("Content-Type"), "application/json") {
+			return
+		}
+		err = openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: request,
+			Status:                 writer.Status(),
+			Header:                 writer.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(writer.body.Bytes())),
+			Options:                options,
+		})
+		if err != nil {
+			l.Error("Response does not match the OpenAPI specification", zap.String("operation", route.Operation.OperationID), zap.String("reason", validationMessage(err)))
+		}
+	}, nil
+}
+
+// validationMessage describes err without the schema and the value it holds,
+// which may be large or sensitive.
+func validationMessage(err error) string {
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return err.Error()
+	}
+	field := strings.Join(schemaErr.JSONPointer(), "/")
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) && requestErr.Parameter != nil {
+		field = requestErr.Parameter.Name
+	}
+	if field == "" {
+		return schemaErr.Reason
+	}
+	return field + ": " + schemaErr.Reason
+}
@@ -0,0 +1,71 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sample-crud/api"
+	"sample-crud/internal/middleware"
+	"sample-crud/pkg/response"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newValidatedRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	doc, err := api.Load()
+	if err != nil {
+		t.Fatalf("load document: %v", err)
+	}
+	validation, err := middleware.OpenAPIValidation(doc)
+	if err != nil {
+		t.Fatalf("OpenAPIValidation: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(validation)
+	reached := func(c *gin.Context) {
+		c.JSON(http.StatusMultiStatus, response.BaseResponse{ResponseCode: response.CodePartialSuccess})
+	}
+	router.POST("/api/v1/products", reached)
+	router.POST("/api/v1/products:action", reached)
+	return router
+}
+
+func post(router *gin.Engine, path string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestOpenAPIValidationLeavesBatchItemsToTheHandler(t *testing.T) {
+	router := newValidatedRouter(t)
+
+	for _, path := range []string{"/api/v1/products:batchCreate", "/api/v1/products:batchUpsert"} {
+		recorder := post(router, path, `{"items":[{"name":"Mouse","sku":"M-1"},{"name":"ab","sku":"M-2"}]}`)
+		if recorder.Code != http.StatusMultiStatus {
+			t.Fatalf("expected %s to reach the handler, got %d: %s", path, recorder.Code, recorder.Body)
+		}
+	}
+}
+
+func TestOpenAPIValidationRejectsWithoutSchemaDetails(t *testing.T) {
+	router := newValidatedRouter(t)
+
+	recorder := post(router, "/api/v1/products", `{"name":"ab"}`)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", recorder.Code, recorder.Body)
+	}
+	var body response.BaseResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.ResponseMessage != "name: minimum string length is 3" {
+		t.Fatalf("unexpected message %q", body.ResponseMessage)
+	}
+}
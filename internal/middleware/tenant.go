@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"sample-crud/internal/requestctx"
+	"sample-crud/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tenant scopes the request to its tenant. The subject of a verified token
+// becomes the actor, in place of the one given by the Actor middleware.
+func Tenant(resolver *tenant.Resolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, err := resolver.Resolve(c.GetHeader(requestctx.TenantHeader), c.GetHeader("Authorization"))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		ctx := requestctx.WithTenant(c.Request.Context(), identity.Tenant)
+		if identity.Subject != "" {
+			ctx = requestctx.WithActor(ctx, identity.Subject)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"context"
+	"sample-crud/internal/config"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/repo"
+	"sample-crud/internal/requestctx"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Relay publishes due outbox events to a sink with at-least-once delivery, the
+// events of an aggregate in id order. The events of a round are leased in a
+// short transaction and published after it commits. A round takes at most one
+// event per aggregate, see OutboxRepository.FetchPending, so one that fails to
+// publish is retried with exponential backoff and holds back the later events
+// of its aggregate, but not those of other aggregates.
+type Relay struct {
+	outboxRepository repo.OutboxRepository
+	txManager        repo.TransactionManager
+	sink             Sink
+	cfg              config.OutboxConfig
+	stop             chan struct{}
+	wg               sync.WaitGroup
+}
+
+func (r *Relay) Start() {
+	zap.L().Info("Starting outbox relay", zap.Duration("poll_interval", r.cfg.PollInterval))
+	r.wg.Add(1)
+	go r.run()
+}
+
+func (r *Relay) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+	zap.L().Info("Outbox relay stopped")
+}
+
+func (r *Relay) run() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	lastCleanup := time.Time{}
+	// The relay publishes the events of every tenant.
+	ctx := requestctx.WithAllTenants(context.Background())
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+		for {
+			select {
+			case <-r.stop:
+				return
+			default:
+			}
+			fetched, err := r.publishBatch(ctx)
+			if err != nil {
+				zap.L().Warn("Outbox relay round failed", zap.Error(err))
+			}
+			// The next events of the aggregates just published are due
+			// until a round fetches nothing.
+			if err != nil || fetched == 0 {
+				break
+			}
+		}
+		if time.Since(lastCleanup) >= r.cfg.CleanupInterval {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+	}
+}
+
+// publishBatch publishes one batch of due events and returns how many it
+// fetched. The leases keep the events from other relays until they are marked,
+// or are fetched again once expired, should this relay stop before.
+func (r *Relay) publishBatch(ctx context.Context) (int, error) {
+	now := time.Now()
+	var events []domain.OutboxEvent
+	err := r.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if events, err = r.outboxRepository.FetchPending(ctx, r.cfg.BatchSize, now); err != nil {
+			return err
+		}
+		ids := make([]uint64, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		return r.outboxRepository.Lease(ctx, ids, now.Add(r.cfg.Lease))
+	})
+	if err != nil {
+		return 0, err
+	}
+	var published []uint64
+	for _, event := range events {
+		if err := r.sink.Publish(ctx, event); err != nil {
+			nextAttemptAt := time.Now().Add(r.backoff(event.Attempts))
+			zap.L().Warn("Fail to publish outbox event",
+				zap.Uint64("event_id", event.ID),
+				zap.Int("attempts", event.Attempts+1),
+				zap.Time("next_attempt_at", nextAttemptAt),
+				zap.Error(err))
+			if err := r.outboxRepository.MarkFailed(ctx, event.ID, nextAttemptAt, err.Error()); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+		published = append(published, event.ID)
+	}
+	return len(events), r.outboxRepository.MarkPublished(ctx, published, time.Now())
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	backoff := r.cfg.PollInterval << min(attempts, 16)
+	if backoff <= 0 || backoff > r.cfg.MaxBackoff {
+		return r.cfg.MaxBackoff
+	}
+	return backoff
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.outboxRepository.DeletePublishedBefore(ctx, time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		zap.L().Warn("Fail to clean up delivered outbox events", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		zap.L().Info("Delivered outbox events cleaned up", zap.Int64("deleted", deleted))
+	}
+}
+
+func NewRelay(outboxRepository repo.OutboxRepository, txManager repo.TransactionManager, sink Sink, cfg config.OutboxConfig) *Relay {
+	return &Relay{
+		outboxRepository: outboxRepository,
+		txManager:        txManager,
+		sink:             sink,
+		cfg:              cfg,
+		stop:             make(chan struct{}),
+	}
+}
@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sample-crud/internal/config"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/repo"
+	"sample-crud/internal/repo/repotest"
+	"sample-crud/internal/requestctx"
+	"testing"
+	"time"
+)
+
+// recordingSink fails the events of failing and records the others.
+type recordingSink struct {
+	failing   uint64
+	published []uint64
+}
+
+func (s *recordingSink) Publish(_ context.Context, event domain.OutboxEvent) error {
+	if event.ID == s.failing {
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+// newRelay returns a relay publishing to sink the events appended for the
+// given aggregate ids, in that order.
+func newRelay(t *testing.T, sink Sink, aggregateIDs ...string) (*Relay, repo.OutboxRepository) {
+	t.Helper()
+	resolver := repotest.OpenSQLite(t)
+	outboxRepository := repo.NewGormOutboxRepository(resolver)
+	ctx := requestctx.WithAllTenants(context.Background())
+	for _, aggregateID := range aggregateIDs {
+		event := &domain.OutboxEvent{TenantID: "default", AggregateType: "product", AggregateID: aggregateID, EventType: "product.updated", Payload: "{}"}
+		if err := outboxRepository.Append(ctx, event); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	relay := NewRelay(outboxRepository, repo.NewGormTransactionManager(resolver, repo.ParseIsolationLevel(""), 0), sink, config.OutboxConfig{
+		BatchSize:    10,
+		PollInterval: time.Second,
+		Lease:        time.Minute,
+		MaxBackoff:   time.Minute,
+	})
+	return relay, outboxRepository
+}
+
+func TestFailingEventDoesNotHoldBackOtherAggregates(t *testing.T) {
+	ctx := requestctx.WithAllTenants(context.Background())
+	sink := &recordingSink{failing: 1}
+	relay, outboxRepository := newRelay(t, sink, "1", "2", "3")
+
+	fetched, err := relay.publishBatch(ctx)
+	if err != nil {
+		t.Fatalf("publish batch: %v", err)
+	}
+	if fetched != 3 || len(sink.published) != 2 || sink.published[0] != 2 || sink.published[1] != 3 {
+		t.Fatalf("expected the events after the failing one published, fetched %d and published %v", fetched, sink.published)
+	}
+
+	pending, err := outboxRepository.FetchPending(ctx, 10, time.Now())
+	if err != nil {
+		t.Fatalf("fetch pending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected the failing event to wait for its backoff, got %d due", len(pending))
+	}
+	pending, err = outboxRepository.FetchPending(ctx, 10, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("fetch pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != 1 || pending[0].Attempts != 1 {
+		t.Fatalf("expected only the failing event left with one attempt, got %+v", pending)
+	}
+}
+
+func TestFailingEventHoldsBackTheNextOnesOfItsAggregate(t *testing.T) {
+	ctx := requestctx.WithAllTenants(context.Background())
+	sink := &recordingSink{failing: 2}
+	relay, outboxRepository := newRelay(t, sink, "1", "1", "1")
+
+	for round := 0; round < 3; round++ {
+		if _, err := relay.publishBatch(ctx); err != nil {
+			t.Fatalf("publish batch: %v", err)
+		}
+	}
+	if len(sink.published) != 1 || sink.published[0] != 1 {
+		t.Fatalf("expected only the event before the failing one published, got %v", sink.published)
+	}
+	pending, err := outboxRepository.FetchPending(ctx, 10, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("fetch pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != 2 || pending[0].Attempts != 1 {
+		t.Fatalf("expected the event after the failing one to wait for it, got %+v", pending)
+	}
+
+	// Once the failing event publishes, the held one follows it.
+	sink.failing = 0
+	if err := outboxRepository.MarkFailed(ctx, 2, time.Now(), "sink unavailable"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	for round := 0; round < 2; round++ {
+		if _, err := relay.publishBatch(ctx); err != nil {
+			t.Fatalf("publish batch: %v", err)
+		}
+	}
+	if len(sink.published) != 3 || sink.published[1] != 2 || sink.published[2] != 3 {
+		t.Fatalf("expected the events published in order, got %v", sink.published)
+	}
+}
@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"context"
+	"sample-crud/internal/domain"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+type Sink interface {
+	Publish(ctx context.Context, event domain.OutboxEvent) error
+}
+
+type RedisStreamSink struct {
+	redisClient redis.UniversalClient
+	stream      string
+	maxLen      int64
+}
+
+func (s RedisStreamSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	return s.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"event_id":       strconv.FormatUint(event.ID, 10),
+			"tenant_id":      event.TenantID,
+			"event_type":     event.EventType,
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   event.AggregateID,
+			"payload":        event.Payload,
+			"created_at":     event.CreatedAt.Format(time.RFC3339Nano),
+		},
+	}).Err()
+}
+
+func NewRedisStreamSink(redisClient redis.UniversalClient, stream string, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{
+		redisClient: redisClient,
+		stream:      stream,
+		maxLen:      maxLen,
+	}
+}
+
+type LogSink struct{}
+
+func (s LogSink) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	zap.L().Info("Outbox event published",
+		zap.Uint64("event_id", event.ID),
+		zap.String("tenant_id", event.TenantID),
+		zap.String("event_type", event.EventType),
+		zap.String("aggregate_type", event.AggregateType),
+		zap.String("aggregate_id", event.AggregateID),
+		zap.String("payload", event.Payload))
+	return nil
+}
+
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
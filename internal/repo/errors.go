@@ -0,0 +1,48 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	pgIntegrityViolationClass = "23"
+	pgUniqueViolation         = "23505"
+	productSKUIndex           = "products_tenant_sku_uindex"
+	// SQLite names the columns of the violated index, not the index.
+	sqliteProductSKUColumns = "products.tenant_id, products.sku"
+)
+
+var (
+	// ErrDuplicateSKU is returned when a product would share its sku with
+	// another product of its tenant.
+	ErrDuplicateSKU = errors.New("sku already used by another product")
+	// ErrConstraintViolation is returned when a write breaks any other
+	// integrity constraint.
+	ErrConstraintViolation = errors.New("integrity constraint violated")
+)
+
+// translateError maps the integrity constraint violations of the database to
+// ErrDuplicateSKU and ErrConstraintViolation, keeping the driver error in the
+// chain. Other errors are returned as is.
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && strings.HasPrefix(pgErr.Code, pgIntegrityViolationClass) {
+		if pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == productSKUIndex {
+			return fmt.Errorf("%w: %w", ErrDuplicateSKU, err)
+		}
+		return fmt.Errorf("%w: %w", ErrConstraintViolation, err)
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		if sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique && strings.Contains(sqliteErr.Error(), sqliteProductSKUColumns) {
+			return fmt.Errorf("%w: %w", ErrDuplicateSKU, err)
+		}
+		return fmt.Errorf("%w: %w", ErrConstraintViolation, err)
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"context"
+	"sample-crud/infra/db"
+	"sample-crud/internal/domain"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryOutboxRepository keeps outbox events in memory, for tests that should
+// not need a database. It fetches events in the order of GormOutboxRepository
+// and scopes every call to the tenant of its context.
+type MemoryOutboxRepository struct {
+	mu     sync.Mutex
+	events []domain.OutboxEvent
+	lastID uint64
+}
+
+func (m *MemoryOutboxRepository) Append(ctx context.Context, event *domain.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var err error
+	if event.TenantID, err = db.AssignTenant(ctx, event.TenantID); err != nil {
+		return err
+	}
+	now := time.Now()
+	m.lastID++
+	event.ID = m.lastID
+	event.CreatedAt = now
+	event.NextAttemptAt = now
+	m.events = append(m.events, *event)
+	return nil
+}
+
+// FetchPending returns the oldest unpublished events due at now, each after
+// the earlier events of its aggregate are published.
+func (m *MemoryOutboxRepository) FetchPending(ctx context.Context, limit int, now time.Time) ([]domain.OutboxEvent, error) {
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events := make([]domain.OutboxEvent, 0)
+	held := map[[2]string]bool{}
+	for _, event := range m.events {
+		if len(events) == limit {
+			break
+		}
+		if event.PublishedAt != nil || (tenant != "" && event.TenantID != tenant) {
+			continue
+		}
+		aggregate := [2]string{event.AggregateType, event.AggregateID}
+		if !held[aggregate] && !event.NextAttemptAt.After(now) {
+			events = append(events, event)
+		}
+		held[aggregate] = true
+	}
+	return events, nil
+}
+
+func (m *MemoryOutboxRepository) Lease(ctx context.Context, ids []uint64, until time.Time) error {
+	return m.update(ctx, ids, func(event *domain.OutboxEvent) {
+		event.NextAttemptAt = until
+	})
+}
+
+func (m *MemoryOutboxRepository) MarkPublished(ctx context.Context, ids []uint64, publishedAt time.Time) error {
+	return m.update(ctx, ids, func(event *domain.OutboxEvent) {
+		event.PublishedAt = &publishedAt
+	})
+}
+
+func (m *MemoryOutboxRepository) MarkFailed(ctx context.Context, id uint64, nextAttemptAt time.Time, lastError string) error {
+	return m.update(ctx, []uint64{id}, func(event *domain.OutboxEvent) {
+		event.Attempts++
+		event.NextAttemptAt = nextAttemptAt
+		event.LastError = &lastError
+	})
+}
+
+func (m *MemoryOutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := len(m.events)
+	m.events = slices.DeleteFunc(m.events, func(event domain.OutboxEvent) bool {
+		return (tenant == "" || event.TenantID == tenant) && event.PublishedAt != nil && event.PublishedAt.Before(before)
+	})
+	return int64(count - len(m.events)), nil
+}
+
+func (m *MemoryOutboxRepository) update(ctx context.Context, ids []uint64, fn func(event *domain.OutboxEvent)) error {
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.events {
+		if (tenant == "" || m.events[i].TenantID == tenant) && slices.Contains(ids, m.events[i].ID) {
+			fn(&m.events[i])
+		}
+	}
+	return nil
+}
+
+func (m *MemoryOutboxRepository) snapshot() func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events, lastID := slices.Clone(m.events), m.lastID
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.events, m.lastID = events, lastID
+	}
+}
+
+func NewMemoryOutboxRepository() *MemoryOutboxRepository {
+	return &MemoryOutboxRepository{}
+}
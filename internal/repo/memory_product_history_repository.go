@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"context"
+	"sample-crud/infra/db"
+	"sample-crud/internal/domain"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryProductHistoryRepository keeps the product history in memory, for
+// tests that should not need a database. It scopes every call to the tenant of
+// its context, like GormProductHistoryRepository.
+type MemoryProductHistoryRepository struct {
+	mu      sync.RWMutex
+	entries []domain.ProductHistory
+	lastID  uint64
+}
+
+func (m *MemoryProductHistoryRepository) Append(ctx context.Context, history *domain.ProductHistory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var err error
+	if history.TenantID, err = db.AssignTenant(ctx, history.TenantID); err != nil {
+		return err
+	}
+	m.lastID++
+	history.ID = m.lastID
+	history.CreatedAt = time.Now().UTC()
+	m.entries = append(m.entries, *history)
+	return nil
+}
+
+// ListByProductID returns a page of the entries of the product, newest first.
+func (m *MemoryProductHistoryRepository) ListByProductID(ctx context.Context, productID uint, query domain.PageQuery) ([]domain.ProductHistory, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries, err := m.filter(ctx, func(entry domain.ProductHistory) bool {
+		return entry.ProductID == productID
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	slices.Reverse(entries)
+	start := min(max((query.Page-1)*query.Size, 0), len(entries))
+	end := min(start+query.Size, len(entries))
+	return entries[start:end], int64(len(entries)), nil
+}
+
+// FindLastAt returns the last entry recorded at or before at, or
+// gorm.ErrRecordNotFound.
+func (m *MemoryProductHistoryRepository) FindLastAt(ctx context.Context, productID uint, at time.Time) (*domain.ProductHistory, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries, err := m.filter(ctx, func(entry domain.ProductHistory) bool {
+		return entry.ProductID == productID && !entry.CreatedAt.After(at)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entries[len(entries)-1], nil
+}
+
+// FindFirstAfter returns the first entry recorded after at, or
+// gorm.ErrRecordNotFound.
+func (m *MemoryProductHistoryRepository) FindFirstAfter(ctx context.Context, productID uint, at time.Time) (*domain.ProductHistory, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries, err := m.filter(ctx, func(entry domain.ProductHistory) bool {
+		return entry.ProductID == productID && entry.CreatedAt.After(at)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entries[0], nil
+}
+
+// filter returns the matching entries of the tenant of ctx, oldest first. The
+// entries are appended in time order, the same order as their ids.
+func (m *MemoryProductHistoryRepository) filter(ctx context.Context, match func(entry domain.ProductHistory) bool) ([]domain.ProductHistory, error) {
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]domain.ProductHistory, 0)
+	for _, entry := range m.entries {
+		if (tenant == "" || entry.TenantID == tenant) && match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (m *MemoryProductHistoryRepository) snapshot() func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries, lastID := slices.Clone(m.entries), m.lastID
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.entries, m.lastID = entries, lastID
+	}
+}
+
+func NewMemoryProductHistoryRepository() *MemoryProductHistoryRepository {
+	return &MemoryProductHistoryRepository{}
+}
@@ -0,0 +1,317 @@
+package repo
+
+import (
+	"context"
+	"maps"
+	"sample-crud/infra/db"
+	"sample-crud/internal/domain"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryProductRepository keeps products in memory, for tests that should not
+// need Postgres. It reports the same errors as the database implementations,
+// a duplicate sku failing with ErrDuplicateSKU, and scopes every call to
+// the tenant of its context. Its writes are rolled back with the failed
+// transactions of a MemoryTransactionManager.
+type MemoryProductRepository struct {
+	mu       sync.RWMutex
+	products map[uint]domain.Product
+	lastID   uint
+}
+
+func (m *MemoryProductRepository) Create(ctx context.Context, product *domain.Product) (uint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var now = time.Now().UTC()
+	product.ID = 0
+	product.CreatedAt = &now
+	product.UpdatedAt = &now
+	return m.insert(ctx, product)
+}
+
+func (m *MemoryProductRepository) FindByID(ctx context.Context, id uint) (*domain.Product, error) {
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	product, ok := m.lookup(tenant, id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	product = copyProduct(product)
+	return &product, nil
+}
+
+func (m *MemoryProductRepository) FindByIDs(ctx context.Context, ids []uint) ([]domain.Product, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.filter(ctx, func(product domain.Product) bool {
+		for _, id := range ids {
+			if product.ID == id {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (m *MemoryProductRepository) FindBySKUs(ctx context.Context, skus []string) ([]domain.Product, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.filter(ctx, func(product domain.Product) bool {
+		for _, sku := range skus {
+			if product.SKU != nil && *product.SKU == sku {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (m *MemoryProductRepository) FindRecentlyUpdated(ctx context.Context, limit int) ([]domain.Product, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	products, err := m.filter(ctx, func(domain.Product) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(products, func(i, j int) bool {
+		a, b := products[i].UpdatedAt, products[j].UpdatedAt
+		switch {
+		case a == nil && b == nil:
+			return products[i].ID > products[j].ID
+		case a == nil || b == nil:
+			return a != nil
+		case !a.Equal(*b):
+			return a.After(*b)
+		default:
+			return products[i].ID > products[j].ID
+		}
+	})
+	return products[:min(limit, len(products))], nil
+}
+
+func (m *MemoryProductRepository) List(ctx context.Context, query domain.ProductListQuery) ([]domain.Product, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	needle := strings.ToLower(query.Query)
+	products, err := m.filter(ctx, func(product domain.Product) bool {
+		return strings.Contains(strings.ToLower(product.Name), needle)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	start := min(max((query.Page-1)*query.Size, 0), len(products))
+	end := min(start+query.Size, len(products))
+	return products[start:end], int64(len(products)), nil
+}
+
+func (m *MemoryProductRepository) ListAfter(ctx context.Context, query string, afterID uint, limit int) ([]domain.Product, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	needle := strings.ToLower(query)
+	products, err := m.filter(ctx, func(product domain.Product) bool {
+		return product.ID > afterID && strings.Contains(strings.ToLower(product.Name), needle)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products[:min(limit, len(products))], nil
+}
+
+func (m *MemoryProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var now = time.Now().UTC()
+	product.UpdatedAt = &now
+	existing, ok := m.lookup(tenant, product.ID)
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	product.TenantID = existing.TenantID
+	if err := m.checkSKU(product); err != nil {
+		return err
+	}
+	m.products[product.ID] = copyProduct(*product)
+	return nil
+}
+
+func (m *MemoryProductRepository) Delete(ctx context.Context, id uint) (int64, error) {
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.lookup(tenant, id); !ok {
+		return 0, nil
+	}
+	delete(m.products, id)
+	return 1, nil
+}
+
+func (m *MemoryProductRepository) CreateBatch(ctx context.Context, products []*domain.Product) []BatchResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().UTC()
+	results := make([]BatchResult, len(products))
+	for i, product := range products {
+		product.ID = 0
+		product.CreatedAt = &now
+		product.UpdatedAt = &now
+		id, err := m.insert(ctx, product)
+		results[i] = BatchResult{ID: id, Err: err}
+	}
+	return results
+}
+
+func (m *MemoryProductRepository) UpsertBySKU(ctx context.Context, products []*domain.Product) []BatchResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().UTC()
+	results := make([]BatchResult, len(products))
+	for i, product := range products {
+		product.ID = 0
+		product.CreatedAt = &now
+		product.UpdatedAt = &now
+		var err error
+		if product.TenantID, err = db.AssignTenant(ctx, product.TenantID); err != nil {
+			results[i] = BatchResult{Err: err}
+			continue
+		}
+		if existing, ok := m.findBySKU(product.TenantID, product.SKU); ok {
+			existing.Name = product.Name
+			existing.UpdatedAt = &now
+			m.products[existing.ID] = existing
+			product.ID = existing.ID
+			results[i] = BatchResult{ID: existing.ID}
+			continue
+		}
+		id, err := m.insert(ctx, product)
+		results[i] = BatchResult{ID: id, Err: err}
+	}
+	return results
+}
+
+func (m *MemoryProductRepository) DeleteBatch(ctx context.Context, ids []uint) []BatchResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results := make([]BatchResult, len(ids))
+	tenant, err := db.TenantFilter(ctx)
+	for i, id := range ids {
+		results[i] = BatchResult{ID: id, Err: err}
+		if err != nil {
+			continue
+		}
+		if _, ok := m.lookup(tenant, id); !ok {
+			results[i].Err = gorm.ErrRecordNotFound
+			continue
+		}
+		delete(m.products, id)
+	}
+	return results
+}
+
+func (m *MemoryProductRepository) snapshot() func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	products, lastID := maps.Clone(m.products), m.lastID
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.products, m.lastID = products, lastID
+	}
+}
+
+func (m *MemoryProductRepository) insert(ctx context.Context, product *domain.Product) (uint, error) {
+	var err error
+	if product.TenantID, err = db.AssignTenant(ctx, product.TenantID); err != nil {
+		return 0, err
+	}
+	if err := m.checkSKU(product); err != nil {
+		return 0, err
+	}
+	m.lastID++
+	product.ID = m.lastID
+	m.products[product.ID] = copyProduct(*product)
+	return product.ID, nil
+}
+
+// checkSKU fails like the products_tenant_sku_uindex unique index would.
+func (m *MemoryProductRepository) checkSKU(product *domain.Product) error {
+	if existing, ok := m.findBySKU(product.TenantID, product.SKU); ok && existing.ID != product.ID {
+		return ErrDuplicateSKU
+	}
+	return nil
+}
+
+func (m *MemoryProductRepository) findBySKU(tenant string, sku *string) (domain.Product, bool) {
+	if sku == nil {
+		return domain.Product{}, false
+	}
+	for _, product := range m.products {
+		if product.TenantID == tenant && product.SKU != nil && *product.SKU == *sku {
+			return product, true
+		}
+	}
+	return domain.Product{}, false
+}
+
+// lookup finds a product of tenant, or of any tenant when it is empty.
+func (m *MemoryProductRepository) lookup(tenant string, id uint) (domain.Product, bool) {
+	product, ok := m.products[id]
+	if !ok || (tenant != "" && product.TenantID != tenant) {
+		return domain.Product{}, false
+	}
+	return product, true
+}
+
+// filter returns copies of the matching products of the tenant of ctx ordered
+// by id.
+func (m *MemoryProductRepository) filter(ctx context.Context, match func(product domain.Product) bool) ([]domain.Product, error) {
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	products := make([]domain.Product, 0)
+	for _, product := range m.products {
+		if (tenant == "" || product.TenantID == tenant) && match(product) {
+			products = append(products, copyProduct(product))
+		}
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
+
+// copyProduct keeps callers from changing stored products through the pointer
+// fields.
+func copyProduct(product domain.Product) domain.Product {
+	if product.SKU != nil {
+		sku := *product.SKU
+		product.SKU = &sku
+	}
+	if product.CreatedAt != nil {
+		createdAt := *product.CreatedAt
+		product.CreatedAt = &createdAt
+	}
+	if product.UpdatedAt != nil {
+		updatedAt := *product.UpdatedAt
+		product.UpdatedAt = &updatedAt
+	}
+	return product
+}
+
+func NewMemoryProductRepository() *MemoryProductRepository {
+	return &MemoryProductRepository{products: map[uint]domain.Product{}}
+}
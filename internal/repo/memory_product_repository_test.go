@@ -0,0 +1,13 @@
+package repo_test
+
+import (
+	"sample-crud/internal/repo"
+	"sample-crud/internal/repo/repotest"
+	"testing"
+)
+
+func TestMemoryProductRepository(t *testing.T) {
+	repotest.RunProductRepositoryContract(t, func(t *testing.T) repo.ProductRepository {
+		return repo.NewMemoryProductRepository()
+	})
+}
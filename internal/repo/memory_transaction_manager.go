@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+	"sync"
+)
+
+type memoryTxKey struct{}
+
+// memoryStore is an in-memory repository whose state a
+// MemoryTransactionManager can roll back.
+type memoryStore interface {
+	// snapshot saves the state of the store and returns the function restoring
+	// it.
+	snapshot() (restore func())
+}
+
+// MemoryTransactionManager runs transactions over in-memory repositories, for
+// tests that should not need a database. Transactions run one at a time, and
+// the writes made in a failed one are rolled back in every store it was given;
+// writes made outside of a transaction meanwhile are rolled back with them.
+type MemoryTransactionManager struct {
+	mu     sync.Mutex
+	stores []memoryStore
+}
+
+func (m *MemoryTransactionManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error, _ ...TxOption) error {
+	if ctx.Value(memoryTxKey{}) != nil {
+		return fn(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	restores := make([]func(), len(m.stores))
+	for i, store := range m.stores {
+		restores[i] = store.snapshot()
+	}
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, true)); err != nil {
+		for _, restore := range restores {
+			restore()
+		}
+		return err
+	}
+	return nil
+}
+
+// NewMemoryTransactionManager rolls back the given stores, the in-memory
+// product, outbox and history repositories.
+func NewMemoryTransactionManager(stores ...memoryStore) *MemoryTransactionManager {
+	return &MemoryTransactionManager{stores: stores}
+}
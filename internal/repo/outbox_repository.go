@@ -0,0 +1,104 @@
+package repo
+
+import (
+	"context"
+	"sample-crud/infra/db"
+	"sample-crud/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository interface {
+	Append(ctx context.Context, event *domain.OutboxEvent) error
+	FetchPending(ctx context.Context, limit int, now time.Time) ([]domain.OutboxEvent, error)
+	Lease(ctx context.Context, ids []uint64, until time.Time) error
+	MarkPublished(ctx context.Context, ids []uint64, publishedAt time.Time) error
+	MarkFailed(ctx context.Context, id uint64, nextAttemptAt time.Time, lastError string) error
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type GormOutboxRepository struct {
+	resolver *db.Resolver
+}
+
+func (g GormOutboxRepository) Append(ctx context.Context, event *domain.OutboxEvent) error {
+	now := time.Now()
+	event.ID = 0
+	event.CreatedAt = now
+	event.NextAttemptAt = now
+	return writer(ctx, g.resolver).Create(event).Error
+}
+
+// FetchPending returns the oldest unpublished events due at now and, inside a
+// transaction, locks them, skipping the rows another relay already holds. An
+// event waits until the earlier events of its aggregate are published, due or
+// not, so that the events of an aggregate are published in order, one per
+// call.
+func (g GormOutboxRepository) FetchPending(ctx context.Context, limit int, now time.Time) ([]domain.OutboxEvent, error) {
+	var events []domain.OutboxEvent
+	tx := writer(ctx, g.resolver)
+	earlier := tx.Session(&gorm.Session{NewDB: true}).
+		Table(domain.OutboxEvent{}.TableName(tx.NamingStrategy) + " AS earlier").
+		Select("1").
+		Where("earlier.aggregate_type = outbox_events.aggregate_type AND earlier.aggregate_id = outbox_events.aggregate_id").
+		Where("earlier.published_at IS NULL AND earlier.id < outbox_events.id")
+	err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("published_at IS NULL AND next_attempt_at <= ?", now).
+		Where("NOT EXISTS (?)", earlier).
+		Order("id").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Lease postpones the events until the given time, so that no other relay
+// fetches them while they are being published.
+func (g GormOutboxRepository) Lease(ctx context.Context, ids []uint64, until time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return writer(ctx, g.resolver).Model(&domain.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Update("next_attempt_at", until).Error
+}
+
+func (g GormOutboxRepository) MarkPublished(ctx context.Context, ids []uint64, publishedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return writer(ctx, g.resolver).Model(&domain.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Update("published_at", publishedAt).Error
+}
+
+func (g GormOutboxRepository) MarkFailed(ctx context.Context, id uint64, nextAttemptAt time.Time, lastError string) error {
+	return writer(ctx, g.resolver).Model(&domain.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		}).Error
+}
+
+func (g GormOutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationBulk)
+	defer cancel()
+	result := writer(ctx, g.resolver).
+		Where("published_at IS NOT NULL AND published_at < ?", before).
+		Delete(&domain.OutboxEvent{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func NewGormOutboxRepository(resolver *db.Resolver) *GormOutboxRepository {
+	return &GormOutboxRepository{resolver: resolver}
+}
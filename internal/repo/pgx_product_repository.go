@@ -0,0 +1,532 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sample-crud/infra/db"
+	"sample-crud/infra/metrics"
+	"sample-crud/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+const (
+	// productTable is where the GORM naming strategy puts domain.Product on
+	// Postgres.
+	productTable   = db.Schema + ".products"
+	productColumns = "id, tenant_id, name, sku, created_at, updated_at"
+	// pgxRepository labels the query metrics of PgxProductRepository.
+	pgxRepository = "PgxProductRepository"
+	// tenantScope matches the tenant given as first parameter, or every
+	// tenant when it is empty, see db.TenantFilter.
+	tenantScope = "($1 = '' OR tenant_id = $1)"
+)
+
+// Statements are prepared once per connection under their name and then
+// executed by name, skipping parsing and planning on every call. All of them
+// take the tenant as first parameter.
+var productStatements = map[string]string{
+	"product_find_by_id":          "SELECT " + productColumns + " FROM " + productTable + " WHERE " + tenantScope + " AND id = $2",
+	"product_find_by_ids":         "SELECT " + productColumns + " FROM " + productTable + " WHERE " + tenantScope + " AND id = ANY($2)",
+	"product_find_by_skus":        "SELECT " + productColumns + " FROM " + productTable + " WHERE " + tenantScope + " AND sku = ANY($2)",
+	"product_find_recent":         "SELECT " + productColumns + " FROM " + productTable + " WHERE " + tenantScope + " ORDER BY updated_at DESC NULLS LAST, id DESC LIMIT $2",
+	"product_count":               "SELECT count(*) FROM " + productTable + " WHERE " + tenantScope,
+	"product_list":                "SELECT " + productColumns + " FROM " + productTable + " WHERE " + tenantScope + " ORDER BY id OFFSET $2 LIMIT $3",
+	"product_count_matching":      "SELECT count(*) FROM " + productTable + " WHERE " + tenantScope + " AND LOWER(name) LIKE $2 ESCAPE '\\'",
+	"product_list_matching":       "SELECT " + productColumns + " FROM " + productTable + " WHERE " + tenantScope + " AND LOWER(name) LIKE $2 ESCAPE '\\' ORDER BY id OFFSET $3 LIMIT $4",
+	"product_list_after":          "SELECT " + productColumns + " FROM " + productTable + " WHERE " + tenantScope + " AND id > $2 ORDER BY id LIMIT $3",
+	"product_list_after_matching": "SELECT " + productColumns + " FROM " + productTable + " WHERE " + tenantScope + " AND id > $2 AND LOWER(name) LIKE $3 ESCAPE '\\' ORDER BY id LIMIT $4",
+	"product_insert":              "INSERT INTO " + productTable + " (tenant_id, name, sku, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+	"product_upsert_by_sku": "INSERT INTO " + productTable + " (tenant_id, name, sku, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) " +
+		"ON CONFLICT (tenant_id, sku) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at RETURNING id",
+	"product_update":      "UPDATE " + productTable + " SET name = $3, sku = $4, created_at = $5, updated_at = $6 WHERE " + tenantScope + " AND id = $2",
+	"product_delete":      "DELETE FROM " + productTable + " WHERE " + tenantScope + " AND id = $2",
+	"product_delete_many": "DELETE FROM " + productTable + " WHERE " + tenantScope + " AND id = ANY($2) RETURNING id",
+}
+
+// PgxProductRepository talks to pgx directly on the connections of the GORM
+// pools, so it shares their replica routing and pool settings. Calls made
+// inside a transaction go through GormProductRepository, which owns it. Its
+// statements are reported to the GORM logger and query metrics like those of
+// GormProductRepository, see trace.
+type PgxProductRepository struct {
+	resolver *db.Resolver
+	gorm     *GormProductRepository
+}
+
+func (p PgxProductRepository) Create(ctx context.Context, product *domain.Product) (uint, error) {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationWrite)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.Create(ctx, product)
+	}
+	var now = time.Now().UTC()
+	product.ID = 0
+	product.CreatedAt = &now
+	product.UpdatedAt = &now
+	var err error
+	if product.TenantID, err = db.AssignTenant(ctx, product.TenantID); err != nil {
+		return 0, err
+	}
+	err = p.withConn(ctx, p.resolver.Writer(ctx), func(conn *pgx.Conn) error {
+		if err := prepare(ctx, conn, "product_insert"); err != nil {
+			return err
+		}
+		begin := time.Now()
+		err := conn.QueryRow(ctx, "product_insert", product.TenantID, product.Name, product.SKU, product.CreatedAt, product.UpdatedAt).Scan(&product.ID)
+		p.trace(ctx, "Create", "product_insert", begin, 1, err)
+		return err
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return product.ID, nil
+}
+
+func (p PgxProductRepository) FindByID(ctx context.Context, id uint) (*domain.Product, error) {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.FindByID(ctx, id)
+	}
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var product *domain.Product
+	err = p.withRetriedConn(ctx, p.resolver.Reader, func(conn *pgx.Conn) error {
+		if err := prepare(ctx, conn, "product_find_by_id"); err != nil {
+			return err
+		}
+		begin := time.Now()
+		var err error
+		product, err = scanProduct(conn.QueryRow(ctx, "product_find_by_id", tenant, int64(id)))
+		p.trace(ctx, "FindByID", "product_find_by_id", begin, 1, err)
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (p PgxProductRepository) FindByIDs(ctx context.Context, ids []uint) ([]domain.Product, error) {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.FindByIDs(ctx, ids)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return p.query(ctx, "FindByIDs", "product_find_by_ids", toInt64s(ids))
+}
+
+func (p PgxProductRepository) FindBySKUs(ctx context.Context, skus []string) ([]domain.Product, error) {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.FindBySKUs(ctx, skus)
+	}
+	if len(skus) == 0 {
+		return nil, nil
+	}
+	return p.query(ctx, "FindBySKUs", "product_find_by_skus", skus)
+}
+
+func (p PgxProductRepository) FindRecentlyUpdated(ctx context.Context, limit int) ([]domain.Product, error) {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.FindRecentlyUpdated(ctx, limit)
+	}
+	return p.query(ctx, "FindRecentlyUpdated", "product_find_recent", limit)
+}
+
+// List sends the count and the page in a single round trip.
+func (p PgxProductRepository) List(ctx context.Context, query domain.ProductListQuery) ([]domain.Product, int64, error) {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.List(ctx, query)
+	}
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	countStatement, listStatement := "product_count", "product_list"
+	countArgs := []any{tenant}
+	listArgs := []any{tenant, (query.Page - 1) * query.Size, query.Size}
+	if query.Query != "" {
+		countStatement, listStatement = "product_count_matching", "product_list_matching"
+		pattern := "%" + escapeLike(strings.ToLower(query.Query)) + "%"
+		countArgs = []any{tenant, pattern}
+		listArgs = []any{tenant, pattern, (query.Page - 1) * query.Size, query.Size}
+	}
+	var products []domain.Product
+	var total int64
+	err = p.withRetriedConn(ctx, p.resolver.Reader, func(conn *pgx.Conn) error {
+		if err := prepare(ctx, conn, countStatement, listStatement); err != nil {
+			return err
+		}
+		batch := &pgx.Batch{}
+		batch.Queue(countStatement, countArgs...)
+		batch.Queue(listStatement, listArgs...)
+		begin := time.Now()
+		results := conn.SendBatch(ctx, batch)
+		defer results.Close()
+		err := results.QueryRow().Scan(&total)
+		p.trace(ctx, "List", countStatement, begin, 1, err)
+		if err != nil {
+			return err
+		}
+		rows, err := results.Query()
+		if err == nil {
+			products, err = collectProducts(rows)
+		}
+		p.trace(ctx, "List", listStatement, begin, int64(len(products)), err)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+func (p PgxProductRepository) ListAfter(ctx context.Context, query string, afterID uint, limit int) ([]domain.Product, error) {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.ListAfter(ctx, query, afterID, limit)
+	}
+	if query == "" {
+		return p.query(ctx, "ListAfter", "product_list_after", int64(afterID), limit)
+	}
+	return p.query(ctx, "ListAfter", "product_list_after_matching", int64(afterID), "%"+escapeLike(strings.ToLower(query))+"%", limit)
+}
+
+func (p PgxProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationWrite)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.Update(ctx, product)
+	}
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return err
+	}
+	var now = time.Now().UTC()
+	product.UpdatedAt = &now
+	err = p.withConn(ctx, p.resolver.Writer(ctx), func(conn *pgx.Conn) error {
+		if err := prepare(ctx, conn, "product_update"); err != nil {
+			return err
+		}
+		begin := time.Now()
+		tag, err := conn.Exec(ctx, "product_update", tenant, int64(product.ID), product.Name, product.SKU, product.CreatedAt, product.UpdatedAt)
+		p.trace(ctx, "Update", "product_update", begin, tag.RowsAffected(), err)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	return translateError(err)
+}
+
+func (p PgxProductRepository) Delete(ctx context.Context, id uint) (int64, error) {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationWrite)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.Delete(ctx, id)
+	}
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	err = p.withConn(ctx, p.resolver.Writer(ctx), func(conn *pgx.Conn) error {
+		if err := prepare(ctx, conn, "product_delete"); err != nil {
+			return err
+		}
+		begin := time.Now()
+		tag, err := conn.Exec(ctx, "product_delete", tenant, int64(id))
+		deleted = tag.RowsAffected()
+		p.trace(ctx, "Delete", "product_delete", begin, deleted, err)
+		return err
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return deleted, nil
+}
+
+func (p PgxProductRepository) CreateBatch(ctx context.Context, products []*domain.Product) []BatchResult {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationBulk)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.CreateBatch(ctx, products)
+	}
+	return p.writeBatch(ctx, "CreateBatch", products, "product_insert")
+}
+
+func (p PgxProductRepository) UpsertBySKU(ctx context.Context, products []*domain.Product) []BatchResult {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationBulk)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.UpsertBySKU(ctx, products)
+	}
+	return p.writeBatch(ctx, "UpsertBySKU", products, "product_upsert_by_sku")
+}
+
+func (p PgxProductRepository) DeleteBatch(ctx context.Context, ids []uint) []BatchResult {
+	ctx, cancel := p.resolver.WithTimeout(ctx, db.OperationBulk)
+	defer cancel()
+	if inTx(ctx) {
+		return p.gorm.DeleteBatch(ctx, ids)
+	}
+	results := make([]BatchResult, len(ids))
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		for i, id := range ids {
+			results[i] = BatchResult{ID: id, Err: err}
+		}
+		return results
+	}
+	for start := 0; start < len(ids); start += batchChunkSize {
+		end := min(start+batchChunkSize, len(ids))
+		found := map[uint]bool{}
+		err := p.withConn(ctx, p.resolver.Writer(ctx), func(conn *pgx.Conn) error {
+			if err := prepare(ctx, conn, "product_delete_many"); err != nil {
+				return err
+			}
+			begin := time.Now()
+			rows, err := conn.Query(ctx, "product_delete_many", tenant, toInt64s(ids[start:end]))
+			var deleted []int64
+			if err == nil {
+				deleted, err = pgx.CollectRows(rows, pgx.RowTo[int64])
+			}
+			p.trace(ctx, "DeleteBatch", "product_delete_many", begin, int64(len(deleted)), err)
+			for _, id := range deleted {
+				found[uint(id)] = true
+			}
+			return err
+		})
+		for i := start; i < end; i++ {
+			switch {
+			case err != nil:
+				results[i] = BatchResult{ID: ids[i], Err: err}
+			case found[ids[i]]:
+				results[i] = BatchResult{ID: ids[i]}
+			default:
+				results[i] = BatchResult{ID: ids[i], Err: gorm.ErrRecordNotFound}
+			}
+		}
+	}
+	return results
+}
+
+// writeBatch queues one statement per product and sends each chunk in a
+// single round trip. A batch runs in an implicit transaction, so when one
+// statement fails the chunk is replayed row by row to isolate the failures.
+func (p PgxProductRepository) writeBatch(ctx context.Context, method string, products []*domain.Product, statement string) []BatchResult {
+	now := time.Now().UTC()
+	results := make([]BatchResult, len(products))
+	for _, product := range products {
+		product.ID = 0
+		product.CreatedAt = &now
+		product.UpdatedAt = &now
+		var err error
+		if product.TenantID, err = db.AssignTenant(ctx, product.TenantID); err != nil {
+			for i := range products {
+				results[i] = BatchResult{Err: err}
+			}
+			return results
+		}
+	}
+	for start := 0; start < len(products); start += batchChunkSize {
+		end := min(start+batchChunkSize, len(products))
+		err := p.withConn(ctx, p.resolver.Writer(ctx), func(conn *pgx.Conn) error {
+			if err := prepare(ctx, conn, statement); err != nil {
+				return err
+			}
+			batch := &pgx.Batch{}
+			for _, product := range products[start:end] {
+				batch.Queue(statement, product.TenantID, product.Name, product.SKU, product.CreatedAt, product.UpdatedAt)
+			}
+			begin := time.Now()
+			batchResults := conn.SendBatch(ctx, batch)
+			for i, product := range products[start:end] {
+				if err := batchResults.QueryRow().Scan(&product.ID); err != nil {
+					_ = batchResults.Close()
+					p.trace(ctx, method, statement, begin, int64(i), err)
+					return err
+				}
+			}
+			err := batchResults.Close()
+			p.trace(ctx, method, statement, begin, int64(end-start), err)
+			return err
+		})
+		if err == nil {
+			for i := start; i < end; i++ {
+				results[i] = BatchResult{ID: products[i].ID}
+			}
+			continue
+		}
+		for i := start; i < end; i++ {
+			products[i].ID = 0
+			err := p.withConn(ctx, p.resolver.Writer(ctx), func(conn *pgx.Conn) error {
+				if err := prepare(ctx, conn, statement); err != nil {
+					return err
+				}
+				begin := time.Now()
+				err := conn.QueryRow(ctx, statement, products[i].TenantID, products[i].Name, products[i].SKU, products[i].CreatedAt, products[i].UpdatedAt).Scan(&products[i].ID)
+				p.trace(ctx, method, statement, begin, 1, err)
+				return err
+			})
+			results[i] = BatchResult{ID: products[i].ID, Err: translateError(err)}
+		}
+	}
+	return results
+}
+
+// query runs a statement of method returning products, scoped to the tenant of
+// ctx.
+func (p PgxProductRepository) query(ctx context.Context, method string, statement string, args ...any) ([]domain.Product, error) {
+	tenant, err := db.TenantFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args = append([]any{tenant}, args...)
+	var products []domain.Product
+	err = p.withRetriedConn(ctx, p.resolver.Reader, func(conn *pgx.Conn) error {
+		if err := prepare(ctx, conn, statement); err != nil {
+			return err
+		}
+		begin := time.Now()
+		rows, err := conn.Query(ctx, statement, args...)
+		if err == nil {
+			products, err = collectProducts(rows)
+		}
+		p.trace(ctx, method, statement, begin, int64(len(products)), err)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// withConn borrows a connection from the pool behind gormDB and hands its
+// underlying pgx connection to fn.
+func (p PgxProductRepository) withConn(ctx context.Context, gormDB *gorm.DB, fn func(conn *pgx.Conn) error) error {
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected database driver connection %T", driverConn)
+		}
+		return fn(stdlibConn.Conn())
+	})
+}
+
+// withRetriedConn is withConn for reads, run again on a newly picked
+// connection after a transient error. Writes are not retried, see
+// GormProductRepository.Delete.
+func (p PgxProductRepository) withRetriedConn(ctx context.Context, pick func(ctx context.Context) *gorm.DB, fn func(conn *pgx.Conn) error) error {
+	return p.resolver.Retry(ctx, func() error {
+		return p.withConn(ctx, pick(ctx), fn)
+	})
+}
+
+// trace reports a statement run by method to the GORM logger and the query
+// duration histogram, as GORM does for its own statements.
+func (p PgxProductRepository) trace(ctx context.Context, method string, statement string, begin time.Time, rows int64, err error) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = gorm.ErrRecordNotFound
+	}
+	sql := productStatements[statement]
+	p.resolver.Primary().Logger.Trace(ctx, begin, func() (string, int64) { return sql, rows }, err)
+	metrics.ObserveQuery(pgxRepository, method, statementOperation(sql), begin, err)
+}
+
+// statementOperation labels a statement like the GORM callback that would
+// have run it.
+func statementOperation(sql string) string {
+	switch {
+	case strings.HasPrefix(sql, "SELECT"):
+		return "query"
+	case strings.HasPrefix(sql, "INSERT"):
+		return "create"
+	case strings.HasPrefix(sql, "UPDATE"):
+		return "update"
+	case strings.HasPrefix(sql, "DELETE"):
+		return "delete"
+	default:
+		return "raw"
+	}
+}
+
+// prepare is a no-op for statements the connection has already prepared.
+func prepare(ctx context.Context, conn *pgx.Conn, statements ...string) error {
+	for _, name := range statements {
+		if _, err := conn.Prepare(ctx, name, productStatements[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanProduct(row pgx.Row) (*domain.Product, error) {
+	var product domain.Product
+	var id int64
+	if err := row.Scan(&id, &product.TenantID, &product.Name, &product.SKU, &product.CreatedAt, &product.UpdatedAt); err != nil {
+		return nil, err
+	}
+	product.ID = uint(id)
+	return &product, nil
+}
+
+func collectProducts(rows pgx.Rows) ([]domain.Product, error) {
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Product, error) {
+		product, err := scanProduct(row)
+		if err != nil {
+			return domain.Product{}, err
+		}
+		return *product, nil
+	})
+}
+
+func toInt64s(ids []uint) []int64 {
+	values := make([]int64, len(ids))
+	for i, id := range ids {
+		values[i] = int64(id)
+	}
+	return values
+}
+
+func inTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return ok
+}
+
+func NewPgxProductRepository(resolver *db.Resolver) *PgxProductRepository {
+	return &PgxProductRepository{resolver: resolver, gorm: NewGormProductRepository(resolver)}
+}
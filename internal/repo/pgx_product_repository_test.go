@@ -0,0 +1,15 @@
+package repo_test
+
+import (
+	"sample-crud/internal/repo"
+	"sample-crud/internal/repo/repotest"
+	"testing"
+)
+
+func TestPgxProductRepository(t *testing.T) {
+	resolver := repotest.OpenPostgres(t)
+	repotest.RunProductRepositoryContract(t, func(t *testing.T) repo.ProductRepository {
+		repotest.TruncateProducts(t, resolver)
+		return repo.NewPgxProductRepository(resolver)
+	})
+}
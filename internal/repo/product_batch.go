@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sample-crud/infra/db"
+	"sample-crud/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const batchChunkSize = 100
+
+// BatchResult is the outcome of one item of a batch, at the same index as the
+// item. Err is gorm.ErrRecordNotFound when a deleted id does not exist, and
+// ErrDuplicateSKU or ErrConstraintViolation when the item breaks a constraint.
+type BatchResult struct {
+	ID  uint
+	Err error
+}
+
+func (g GormProductRepository) FindBySKUs(ctx context.Context, skus []string) ([]domain.Product, error) {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	var products []domain.Product
+	if len(skus) == 0 {
+		return products, nil
+	}
+	err := retry(ctx, g.resolver, func() error {
+		return reader(ctx, g.resolver).Where("sku IN ?", skus).Find(&products).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// CreateBatch inserts products with one multi-row statement per chunk. When a
+// chunk fails its rows are retried one by one to find out which ones fail.
+func (g GormProductRepository) CreateBatch(ctx context.Context, products []*domain.Product) []BatchResult {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationBulk)
+	defer cancel()
+	now := time.Now().UTC()
+	for _, product := range products {
+		product.ID = 0
+		product.CreatedAt = &now
+		product.UpdatedAt = &now
+	}
+	return g.writeInChunks(ctx, products, func(tx *gorm.DB, chunk []*domain.Product) error {
+		return tx.Create(&chunk).Error
+	})
+}
+
+// UpsertBySKU inserts products, or updates the name of the existing product
+// with the same sku in the tenant, keeping its id and creation time.
+func (g GormProductRepository) UpsertBySKU(ctx context.Context, products []*domain.Product) []BatchResult {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationBulk)
+	defer cancel()
+	now := time.Now().UTC()
+	for _, product := range products {
+		product.ID = 0
+		product.CreatedAt = &now
+		product.UpdatedAt = &now
+	}
+	return g.writeInChunks(ctx, products, func(tx *gorm.DB, chunk []*domain.Product) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "sku"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+		}).Create(&chunk).Error
+	})
+}
+
+func (g GormProductRepository) DeleteBatch(ctx context.Context, ids []uint) []BatchResult {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationBulk)
+	defer cancel()
+	results := make([]BatchResult, len(ids))
+	for start := 0; start < len(ids); start += batchChunkSize {
+		end := min(start+batchChunkSize, len(ids))
+		var deleted []domain.Product
+		err := g.inSavepoint(ctx, func(tx *gorm.DB) error {
+			return tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+				Where("id IN ?", ids[start:end]).
+				Delete(&deleted).Error
+		})
+		found := make(map[uint]bool, len(deleted))
+		for _, product := range deleted {
+			found[product.ID] = true
+		}
+		for i := start; i < end; i++ {
+			switch {
+			case err != nil:
+				results[i] = BatchResult{ID: ids[i], Err: err}
+			case found[ids[i]]:
+				results[i] = BatchResult{ID: ids[i]}
+			default:
+				results[i] = BatchResult{ID: ids[i], Err: gorm.ErrRecordNotFound}
+			}
+		}
+	}
+	return results
+}
+
+func (g GormProductRepository) writeInChunks(ctx context.Context, products []*domain.Product, write func(tx *gorm.DB, chunk []*domain.Product) error) []BatchResult {
+	results := make([]BatchResult, len(products))
+	for start := 0; start < len(products); start += batchChunkSize {
+		end := min(start+batchChunkSize, len(products))
+		err := g.inSavepoint(ctx, func(tx *gorm.DB) error {
+			return write(tx, products[start:end])
+		})
+		if err == nil {
+			for i := start; i < end; i++ {
+				results[i] = BatchResult{ID: products[i].ID}
+			}
+			continue
+		}
+		for i := start; i < end; i++ {
+			products[i].ID = 0
+			err := g.inSavepoint(ctx, func(tx *gorm.DB) error {
+				return write(tx, products[i:i+1])
+			})
+			results[i] = BatchResult{ID: products[i].ID, Err: translateError(err)}
+		}
+	}
+	return results
+}
+
+// inSavepoint runs fn on the primary. Inside a transaction it is wrapped in a
+// savepoint, so that a failing statement does not abort the whole transaction.
+func (g GormProductRepository) inSavepoint(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	tx := writer(ctx, g.resolver)
+	if !inTx(ctx) {
+		return fn(tx)
+	}
+	savepoint := fmt.Sprintf("batch_%d", time.Now().UnixNano())
+	if err := tx.SavePoint(savepoint).Error; err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.RollbackTo(savepoint).Error; rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"context"
+	"sample-crud/infra/db"
+	"sample-crud/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProductHistoryRepository interface {
+	Append(ctx context.Context, history *domain.ProductHistory) error
+	ListByProductID(ctx context.Context, productID uint, query domain.PageQuery) ([]domain.ProductHistory, int64, error)
+	FindLastAt(ctx context.Context, productID uint, at time.Time) (*domain.ProductHistory, error)
+	FindFirstAfter(ctx context.Context, productID uint, at time.Time) (*domain.ProductHistory, error)
+}
+
+type GormProductHistoryRepository struct {
+	resolver *db.Resolver
+}
+
+func (g GormProductHistoryRepository) Append(ctx context.Context, history *domain.ProductHistory) error {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationWrite)
+	defer cancel()
+	history.ID = 0
+	// Stored in UTC, SQLite comparing timestamps as text, see FindLastAt.
+	history.CreatedAt = time.Now().UTC()
+	return writer(ctx, g.resolver).Create(history).Error
+}
+
+func (g GormProductHistoryRepository) ListByProductID(ctx context.Context, productID uint, query domain.PageQuery) ([]domain.ProductHistory, int64, error) {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	var history []domain.ProductHistory
+	var total int64
+	err := retry(ctx, g.resolver, func() error {
+		tx := reader(ctx, g.resolver).Model(&domain.ProductHistory{}).Where("product_id = ?", productID).Session(&gorm.Session{})
+		if err := tx.Count(&total).Error; err != nil {
+			return err
+		}
+		return tx.Order("created_at DESC").Order("id DESC").Offset((query.Page - 1) * query.Size).Limit(query.Size).Find(&history).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return history, total, nil
+}
+
+// FindLastAt returns the last entry recorded at or before at, or
+// gorm.ErrRecordNotFound. at is compared in UTC, like the entries are written.
+func (g GormProductHistoryRepository) FindLastAt(ctx context.Context, productID uint, at time.Time) (*domain.ProductHistory, error) {
+	return g.findOne(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("product_id = ? AND created_at <= ?", productID, at.UTC()).Order("created_at DESC").Order("id DESC")
+	})
+}
+
+// FindFirstAfter returns the first entry recorded after at, or
+// gorm.ErrRecordNotFound.
+func (g GormProductHistoryRepository) FindFirstAfter(ctx context.Context, productID uint, at time.Time) (*domain.ProductHistory, error) {
+	return g.findOne(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("product_id = ? AND created_at > ?", productID, at.UTC()).Order("created_at").Order("id")
+	})
+}
+
+func (g GormProductHistoryRepository) findOne(ctx context.Context, scope func(tx *gorm.DB) *gorm.DB) (*domain.ProductHistory, error) {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	var history domain.ProductHistory
+	err := retry(ctx, g.resolver, func() error {
+		return scope(reader(ctx, g.resolver)).Take(&history).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+func NewGormProductHistoryRepository(resolver *db.Resolver) *GormProductHistoryRepository {
+	return &GormProductHistoryRepository{resolver: resolver}
+}
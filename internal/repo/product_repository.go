@@ -2,7 +2,9 @@ package repo
 
 import (
 	"context"
+	"sample-crud/infra/db"
 	"sample-crud/internal/domain"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,51 +13,155 @@ import (
 type ProductRepository interface {
 	Create(ctx context.Context, product *domain.Product) (uint, error)
 	FindByID(ctx context.Context, id uint) (*domain.Product, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]domain.Product, error)
+	FindRecentlyUpdated(ctx context.Context, limit int) ([]domain.Product, error)
+	List(ctx context.Context, query domain.ProductListQuery) ([]domain.Product, int64, error)
+	ListAfter(ctx context.Context, query string, afterID uint, limit int) ([]domain.Product, error)
 	Update(ctx context.Context, product *domain.Product) error
 	Delete(ctx context.Context, id uint) (int64, error)
+	FindBySKUs(ctx context.Context, skus []string) ([]domain.Product, error)
+	CreateBatch(ctx context.Context, products []*domain.Product) []BatchResult
+	UpsertBySKU(ctx context.Context, products []*domain.Product) []BatchResult
+	DeleteBatch(ctx context.Context, ids []uint) []BatchResult
 }
 
 type GormProductRepository struct {
-	db *gorm.DB
+	resolver *db.Resolver
 }
 
 func (g GormProductRepository) Create(ctx context.Context, product *domain.Product) (uint, error) {
-	var now = time.Now()
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationWrite)
+	defer cancel()
+	// Stored in UTC, like the history, SQLite comparing timestamps as text.
+	var now = time.Now().UTC()
 	product.ID = 0
 	product.CreatedAt = &now
 	product.UpdatedAt = &now
-	if err := g.db.WithContext(ctx).Create(product).Error; err != nil {
-		return 0, err
+	if err := writer(ctx, g.resolver).Create(product).Error; err != nil {
+		return 0, translateError(err)
 	}
 	return product.ID, nil
 }
 
 func (g GormProductRepository) FindByID(ctx context.Context, id uint) (*domain.Product, error) {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
 	var product domain.Product
-	if err := g.db.WithContext(ctx).First(&product, id).Error; err != nil {
+	err := retry(ctx, g.resolver, func() error {
+		return reader(ctx, g.resolver).First(&product, id).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
+func (g GormProductRepository) FindByIDs(ctx context.Context, ids []uint) ([]domain.Product, error) {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	var products []domain.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+	err := retry(ctx, g.resolver, func() error {
+		return reader(ctx, g.resolver).Where("id IN ?", ids).Find(&products).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (g GormProductRepository) FindRecentlyUpdated(ctx context.Context, limit int) ([]domain.Product, error) {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	var products []domain.Product
+	err := retry(ctx, g.resolver, func() error {
+		return reader(ctx, g.resolver).Order("updated_at DESC NULLS LAST").Order("id DESC").Limit(limit).Find(&products).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (g GormProductRepository) List(ctx context.Context, query domain.ProductListQuery) ([]domain.Product, int64, error) {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	var products []domain.Product
+	var total int64
+	err := retry(ctx, g.resolver, func() error {
+		tx := reader(ctx, g.resolver).Model(&domain.Product{})
+		if query.Query != "" {
+			tx = tx.Where("LOWER(name) LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(query.Query))+"%")
+		}
+		tx = tx.Session(&gorm.Session{})
+		if err := tx.Count(&total).Error; err != nil {
+			return err
+		}
+		return tx.Order("id").Offset((query.Page - 1) * query.Size).Limit(query.Size).Find(&products).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// ListAfter returns, in id order, the products after afterID whose name
+// contains query, for walking the whole table by key without counting it.
+func (g GormProductRepository) ListAfter(ctx context.Context, query string, afterID uint, limit int) ([]domain.Product, error) {
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationRead)
+	defer cancel()
+	var products []domain.Product
+	err := retry(ctx, g.resolver, func() error {
+		tx := reader(ctx, g.resolver).Where("id > ?", afterID)
+		if query != "" {
+			tx = tx.Where("LOWER(name) LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(query))+"%")
+		}
+		return tx.Order("id").Limit(limit).Find(&products).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
 func (g GormProductRepository) Update(ctx context.Context, product *domain.Product) error {
-	var now = time.Now()
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationWrite)
+	defer cancel()
+	var now = time.Now().UTC()
 	product.UpdatedAt = &now
 
-	if err := g.db.WithContext(ctx).Save(product).Error; err != nil {
-		return err
+	// Save would insert a missing product, report it as not found instead.
+	// Writes are not retried, see isSerializationFailure.
+	result := writer(ctx, g.resolver).Model(product).Select("name", "sku", "created_at", "updated_at").Updates(product)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
 	return nil
 }
 
+// Delete is not retried: a retry after a commit cut by a connection failure
+// would find nothing to delete and report the product as not found.
 func (g GormProductRepository) Delete(ctx context.Context, id uint) (int64, error) {
-	result := g.db.WithContext(ctx).Delete(&domain.Product{}, id)
+	ctx, cancel := g.resolver.WithTimeout(ctx, db.OperationWrite)
+	defer cancel()
+	result := writer(ctx, g.resolver).Delete(&domain.Product{}, id)
 	if result.Error != nil {
-		return 0, result.Error
+		return 0, translateError(result.Error)
 	}
 	return result.RowsAffected, nil
 }
 
-func NewGormProductRepository(db *gorm.DB) *GormProductRepository {
-	return &GormProductRepository{db: db}
+var likeEscaper = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+func NewGormProductRepository(resolver *db.Resolver) *GormProductRepository {
+	return &GormProductRepository{resolver: resolver}
 }
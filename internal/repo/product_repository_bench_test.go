@@ -0,0 +1,185 @@
+package repo_test
+
+import (
+	"context"
+	"fmt"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/repo"
+	"sample-crud/internal/repo/repotest"
+	"sample-crud/internal/requestctx"
+	"testing"
+)
+
+const benchTenant = "bench"
+
+// BenchmarkProductRepository compares the GORM and pgx repositories on the
+// same operations against Postgres, over a fresh table seeded with 100
+// products:
+//
+//	TEST_DATABASE_HOST=localhost go test ./internal/repo -run '^$' -bench ProductRepository -benchmem
+func BenchmarkProductRepository(b *testing.B) {
+	resolver := repotest.OpenPostgres(b)
+	repositories := []struct {
+		name        string
+		productRepo repo.ProductRepository
+	}{
+		{"gorm", repo.NewGormProductRepository(resolver)},
+		{"pgx", repo.NewPgxProductRepository(resolver)},
+	}
+	operations := []struct {
+		name string
+		run  func(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, ids []uint)
+	}{
+		{"FindByID", benchFindByID},
+		{"FindByIDs", benchFindByIDs},
+		{"FindRecentlyUpdated", benchFindRecentlyUpdated},
+		{"List", benchList},
+		{"Create", benchCreate},
+		{"Update", benchUpdate},
+		{"Delete", benchDelete},
+		{"CreateBatch", benchCreateBatch},
+		{"UpsertBySKU", benchUpsertBySKU},
+		{"DeleteBatch", benchDeleteBatch},
+	}
+	ctx := requestctx.WithTenant(context.Background(), benchTenant)
+	for _, operation := range operations {
+		for _, r := range repositories {
+			b.Run(operation.name+"/"+r.name, func(b *testing.B) {
+				repotest.TruncateProducts(b, resolver)
+				ids := seedProducts(b, ctx, r.productRepo, 100)
+				b.ReportAllocs()
+				b.ResetTimer()
+				operation.run(b, ctx, r.productRepo, ids)
+			})
+		}
+	}
+}
+
+func seedProducts(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, n int) []uint {
+	b.Helper()
+	ids := make([]uint, 0, n)
+	for _, result := range productRepo.CreateBatch(ctx, newProducts("seed", 0, n)) {
+		if result.Err != nil {
+			b.Fatalf("seed: %v", result.Err)
+		}
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+// newProducts returns n products with skus unique to prefix and offset.
+func newProducts(prefix string, offset int, n int) []*domain.Product {
+	products := make([]*domain.Product, n)
+	for i := range products {
+		sku := fmt.Sprintf("%s-%d", prefix, offset+i)
+		products[i] = &domain.Product{Name: "Product " + sku, SKU: &sku}
+	}
+	return products
+}
+
+func benchFindByID(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, ids []uint) {
+	for i := 0; i < b.N; i++ {
+		if _, err := productRepo.FindByID(ctx, ids[i%len(ids)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchFindByIDs(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, ids []uint) {
+	for i := 0; i < b.N; i++ {
+		if _, err := productRepo.FindByIDs(ctx, ids); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchFindRecentlyUpdated(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, ids []uint) {
+	for i := 0; i < b.N; i++ {
+		if _, err := productRepo.FindRecentlyUpdated(ctx, len(ids)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchList(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, _ []uint) {
+	for i := 0; i < b.N; i++ {
+		if _, _, err := productRepo.List(ctx, domain.ProductListQuery{Page: 1, Size: 20}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchCreate(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, _ []uint) {
+	for i := 0; i < b.N; i++ {
+		if _, err := productRepo.Create(ctx, newProducts("create", i, 1)[0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchUpdate(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, ids []uint) {
+	for i := 0; i < b.N; i++ {
+		product, err := productRepo.FindByID(ctx, ids[i%len(ids)])
+		if err != nil {
+			b.Fatal(err)
+		}
+		product.Name = fmt.Sprintf("Updated %d", i)
+		if err := productRepo.Update(ctx, product); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchDelete(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, _ []uint) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		id, err := productRepo.Create(ctx, newProducts("delete", i, 1)[0])
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if _, err := productRepo.Delete(ctx, id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchCreateBatch(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, _ []uint) {
+	for i := 0; i < b.N; i++ {
+		for _, result := range productRepo.CreateBatch(ctx, newProducts("batch", i*20, 20)) {
+			if result.Err != nil {
+				b.Fatal(result.Err)
+			}
+		}
+	}
+}
+
+// benchUpsertBySKU updates the seeded products by sku.
+func benchUpsertBySKU(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, _ []uint) {
+	for i := 0; i < b.N; i++ {
+		for _, result := range productRepo.UpsertBySKU(ctx, newProducts("seed", i%5*20, 20)) {
+			if result.Err != nil {
+				b.Fatal(result.Err)
+			}
+		}
+	}
+}
+
+func benchDeleteBatch(b *testing.B, ctx context.Context, productRepo repo.ProductRepository, _ []uint) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ids := make([]uint, 0, 20)
+		for _, result := range productRepo.CreateBatch(ctx, newProducts("delete-batch", i*20, 20)) {
+			if result.Err != nil {
+				b.Fatal(result.Err)
+			}
+			ids = append(ids, result.ID)
+		}
+		b.StartTimer()
+		for _, result := range productRepo.DeleteBatch(ctx, ids) {
+			if result.Err != nil {
+				b.Fatal(result.Err)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package repo_test
+
+import (
+	"context"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/repo"
+	"sample-crud/internal/repo/repotest"
+	"sample-crud/internal/requestctx"
+	"testing"
+)
+
+func TestGormProductRepositorySQLite(t *testing.T) {
+	repotest.RunProductRepositoryContract(t, func(t *testing.T) repo.ProductRepository {
+		return repo.NewGormProductRepository(repotest.OpenSQLite(t))
+	})
+}
+
+func TestGormProductRepositoryPostgres(t *testing.T) {
+	resolver := repotest.OpenPostgres(t)
+	repotest.RunProductRepositoryContract(t, func(t *testing.T) repo.ProductRepository {
+		repotest.TruncateProducts(t, resolver)
+		return repo.NewGormProductRepository(resolver)
+	})
+}
+
+// Both databases in one process, as in CI, must each query their own table.
+func TestGormProductRepositoryOnSQLiteAndPostgres(t *testing.T) {
+	sqlite := repo.NewGormProductRepository(repotest.OpenSQLite(t))
+	resolver := repotest.OpenPostgres(t)
+	repotest.TruncateProducts(t, resolver)
+	postgres := repo.NewGormProductRepository(resolver)
+
+	ctx := requestctx.WithTenant(context.Background(), "default")
+	for name, r := range map[string]repo.ProductRepository{"sqlite": sqlite, "postgres": postgres} {
+		id, err := r.Create(ctx, &domain.Product{Name: "Keyboard"})
+		if err != nil {
+			t.Fatalf("%s: Create: %v", name, err)
+		}
+		if _, err := r.FindByID(ctx, id); err != nil {
+			t.Fatalf("%s: FindByID: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,581 @@
+// Package repotest holds the behaviour every repository implementation must
+// share, to be run from the tests of each implementation.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sample-crud/infra/db"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/repo"
+	"sample-crud/internal/requestctx"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	tenantA = "tenant-a"
+	tenantB = "tenant-b"
+)
+
+// RunProductRepositoryContract runs the contract against the repositories
+// returned by newRepository, which must hold no product. Unless stated
+// otherwise calls are made on behalf of a single tenant.
+//
+//	func TestMemoryProductRepository(t *testing.T) {
+//		repotest.RunProductRepositoryContract(t, func(t *testing.T) repo.ProductRepository {
+//			return repo.NewMemoryProductRepository()
+//		})
+//	}
+func RunProductRepositoryContract(t *testing.T, newRepository func(t *testing.T) repo.ProductRepository) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, r repo.ProductRepository)
+	}{
+		{"CreateAssignsIDAndTimestamps", testCreateAssignsIDAndTimestamps},
+		{"CreateRejectsDuplicateSKU", testCreateRejectsDuplicateSKU},
+		{"CreateIsSafeForConcurrentUse", testCreateIsSafeForConcurrentUse},
+		{"FindByIDReturnsNotFound", testFindByIDReturnsNotFound},
+		{"FindByIDsSkipsMissing", testFindByIDsSkipsMissing},
+		{"FindBySKUs", testFindBySKUs},
+		{"FindRecentlyUpdated", testFindRecentlyUpdated},
+		{"TimestampsDoNotDependOnTheServerTimeZone", testTimestampsDoNotDependOnTheServerTimeZone},
+		{"ListFiltersAndPaginates", testListFiltersAndPaginates},
+		{"ListEscapesWildcards", testListEscapesWildcards},
+		{"ListAfterWalksByID", testListAfterWalksByID},
+		{"Update", testUpdate},
+		{"UpdateReturnsNotFound", testUpdateReturnsNotFound},
+		{"Delete", testDelete},
+		{"CreateBatchReportsEachItem", testCreateBatchReportsEachItem},
+		{"UpsertBySKU", testUpsertBySKU},
+		{"DeleteBatchReportsMissing", testDeleteBatchReportsMissing},
+		{"TenantsCannotReadEachOther", testTenantsCannotReadEachOther},
+		{"TenantsCannotMutateEachOther", testTenantsCannotMutateEachOther},
+		{"TenantIsRequired", testTenantIsRequired},
+		{"AllTenantsScopeSeesEveryTenant", testAllTenantsScopeSeesEveryTenant},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.run(t, newRepository(t))
+		})
+	}
+}
+
+func testCreateAssignsIDAndTimestamps(t *testing.T, r repo.ProductRepository) {
+	ctx := tenantContext(tenantA)
+	first := mustCreate(t, r, "Keyboard", nil)
+	second := mustCreate(t, r, "Mouse", nil)
+	if first.ID == 0 || second.ID <= first.ID {
+		t.Fatalf("expected increasing ids, got %d then %d", first.ID, second.ID)
+	}
+	found, err := r.FindByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Name != "Keyboard" {
+		t.Fatalf("expected name Keyboard, got %q", found.Name)
+	}
+	if found.CreatedAt == nil || found.UpdatedAt == nil {
+		t.Fatalf("expected timestamps to be set, got %v and %v", found.CreatedAt, found.UpdatedAt)
+	}
+	if !found.CreatedAt.Equal(*found.UpdatedAt) {
+		t.Fatalf("expected equal timestamps, got %v and %v", found.CreatedAt, found.UpdatedAt)
+	}
+}
+
+func testCreateRejectsDuplicateSKU(t *testing.T, r repo.ProductRepository) {
+	mustCreate(t, r, "Keyboard", sku("KB-01"))
+	_, err := r.Create(tenantContext(tenantA), &domain.Product{Name: "Other keyboard", SKU: sku("KB-01")})
+	assertDuplicateSKU(t, err)
+}
+
+func testCreateIsSafeForConcurrentUse(t *testing.T, r repo.ProductRepository) {
+	const workers, perWorker = 8, 10
+	var wg sync.WaitGroup
+	ids := make(chan uint, workers*perWorker)
+	errs := make(chan error, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				id, err := r.Create(tenantContext(tenantA), &domain.Product{Name: fmt.Sprintf("Product %d-%d", w, i)})
+				if err != nil {
+					errs <- err
+					continue
+				}
+				ids <- id
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Create: %v", err)
+	}
+	seen := map[uint]bool{}
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("id %d assigned twice", id)
+		}
+		seen[id] = true
+	}
+	_, total, err := r.List(tenantContext(tenantA), domain.ProductListQuery{Page: 1, Size: 1})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != workers*perWorker {
+		t.Fatalf("expected %d products, got %d", workers*perWorker, total)
+	}
+}
+
+func testFindByIDReturnsNotFound(t *testing.T, r repo.ProductRepository) {
+	_, err := r.FindByID(tenantContext(tenantA), 424242)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected gorm.ErrRecordNotFound, got %v", err)
+	}
+}
+
+func testFindByIDsSkipsMissing(t *testing.T, r repo.ProductRepository) {
+	first := mustCreate(t, r, "Keyboard", nil)
+	second := mustCreate(t, r, "Mouse", nil)
+	products, err := r.FindByIDs(tenantContext(tenantA), []uint{second.ID, 424242, first.ID})
+	if err != nil {
+		t.Fatalf("FindByIDs: %v", err)
+	}
+	assertIDs(t, products, first.ID, second.ID)
+	products, err = r.FindByIDs(tenantContext(tenantA), nil)
+	if err != nil || len(products) != 0 {
+		t.Fatalf("expected no product for no id, got %v, %v", products, err)
+	}
+}
+
+func testFindBySKUs(t *testing.T, r repo.ProductRepository) {
+	keyboard := mustCreate(t, r, "Keyboard", sku("KB-01"))
+	mustCreate(t, r, "Mouse", sku("MS-01"))
+	mustCreate(t, r, "Screen", nil)
+	products, err := r.FindBySKUs(tenantContext(tenantA), []string{"KB-01", "unknown"})
+	if err != nil {
+		t.Fatalf("FindBySKUs: %v", err)
+	}
+	assertIDs(t, products, keyboard.ID)
+}
+
+func testFindRecentlyUpdated(t *testing.T, r repo.ProductRepository) {
+	ctx := tenantContext(tenantA)
+	first := mustCreate(t, r, "Keyboard", nil)
+	second := mustCreate(t, r, "Mouse", nil)
+	third := mustCreate(t, r, "Screen", nil)
+	time.Sleep(10 * time.Millisecond)
+	first.Name = "Mechanical keyboard"
+	if err := r.Update(ctx, first); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	products, err := r.FindRecentlyUpdated(ctx, 2)
+	if err != nil {
+		t.Fatalf("FindRecentlyUpdated: %v", err)
+	}
+	if len(products) != 2 || products[0].ID != first.ID {
+		t.Fatalf("expected product %d first among 2, got %v", first.ID, products)
+	}
+	if products[1].ID != third.ID && products[1].ID != second.ID {
+		t.Fatalf("unexpected second product %d", products[1].ID)
+	}
+}
+
+// The time zone of the server can change between writes, products must still
+// be ordered and read by the instant they were written at.
+func testTimestampsDoNotDependOnTheServerTimeZone(t *testing.T, r repo.ProductRepository) {
+	local := time.Local
+	t.Cleanup(func() { time.Local = local })
+	ctx := tenantContext(tenantA)
+
+	time.Local = time.FixedZone("UTC+7", 7*60*60)
+	before := time.Now()
+	first := mustCreate(t, r, "Keyboard", nil)
+	time.Sleep(10 * time.Millisecond)
+	time.Local = time.FixedZone("UTC-5", -5*60*60)
+	second := mustCreate(t, r, "Mouse", nil)
+
+	found, err := r.FindByID(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.CreatedAt == nil || found.CreatedAt.Before(before.Truncate(time.Millisecond)) || found.CreatedAt.After(time.Now()) {
+		t.Fatalf("expected product %d created after %v, got %v", first.ID, before, found.CreatedAt)
+	}
+	products, err := r.FindRecentlyUpdated(ctx, 2)
+	if err != nil {
+		t.Fatalf("FindRecentlyUpdated: %v", err)
+	}
+	if len(products) != 2 || products[0].ID != second.ID {
+		t.Fatalf("expected product %d first among 2, got %v", second.ID, products)
+	}
+}
+
+func testListFiltersAndPaginates(t *testing.T, r repo.ProductRepository) {
+	ctx := tenantContext(tenantA)
+	var matching []uint
+	for i := 0; i < 5; i++ {
+		matching = append(matching, mustCreate(t, r, fmt.Sprintf("Gaming Mouse %d", i), nil).ID)
+		mustCreate(t, r, fmt.Sprintf("Keyboard %d", i), nil)
+	}
+	products, total, err := r.List(ctx, domain.ProductListQuery{Query: "mOUSE", Page: 2, Size: 2})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 5 {
+		t.Fatalf("expected 5 matching products, got %d", total)
+	}
+	assertIDs(t, products, matching[2], matching[3])
+	products, total, err = r.List(ctx, domain.ProductListQuery{Page: 4, Size: 3})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 10 || len(products) != 1 {
+		t.Fatalf("expected the last of 10 products, got %d of %d", len(products), total)
+	}
+	products, _, err = r.List(ctx, domain.ProductListQuery{Page: 5, Size: 3})
+	if err != nil || len(products) != 0 {
+		t.Fatalf("expected an empty page past the end, got %v, %v", products, err)
+	}
+}
+
+func testListAfterWalksByID(t *testing.T, r repo.ProductRepository) {
+	ctx := tenantContext(tenantA)
+	var matching []uint
+	for i := 0; i < 5; i++ {
+		matching = append(matching, mustCreate(t, r, fmt.Sprintf("Gaming Mouse %d", i), nil).ID)
+		mustCreate(t, r, fmt.Sprintf("Keyboard %d", i), nil)
+	}
+	products, err := r.ListAfter(ctx, "mOUSE", matching[1], 2)
+	if err != nil {
+		t.Fatalf("ListAfter: %v", err)
+	}
+	assertIDs(t, products, matching[2], matching[3])
+	products, err = r.ListAfter(ctx, "mouse", matching[3], 2)
+	if err != nil {
+		t.Fatalf("ListAfter: %v", err)
+	}
+	assertIDs(t, products, matching[4])
+	products, err = r.ListAfter(ctx, "", 0, 100)
+	if err != nil || len(products) != 10 {
+		t.Fatalf("expected every product, got %d, %v", len(products), err)
+	}
+	products, err = r.ListAfter(ctx, "", products[9].ID, 100)
+	if err != nil || len(products) != 0 {
+		t.Fatalf("expected nothing past the last product, got %v, %v", products, err)
+	}
+}
+
+func testListEscapesWildcards(t *testing.T, r repo.ProductRepository) {
+	discount := mustCreate(t, r, "Discount 50% off", nil)
+	mustCreate(t, r, "Discount 500 off", nil)
+	snake := mustCreate(t, r, "snake_case", nil)
+	mustCreate(t, r, "snakeXcase", nil)
+	for query, id := range map[string]uint{"50%": discount.ID, "e_c": snake.ID} {
+		products, total, err := r.List(tenantContext(tenantA), domain.ProductListQuery{Query: query, Page: 1, Size: 10})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if total != 1 {
+			t.Fatalf("expected 1 product matching %q, got %d", query, total)
+		}
+		assertIDs(t, products, id)
+	}
+}
+
+func testUpdate(t *testing.T, r repo.ProductRepository) {
+	ctx := tenantContext(tenantA)
+	created := mustCreate(t, r, "Keyboard", nil)
+	product, err := r.FindByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	createdAt := *product.CreatedAt
+	time.Sleep(10 * time.Millisecond)
+	product.Name = "Mechanical keyboard"
+	if err := r.Update(ctx, product); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	updated, err := r.FindByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if updated.Name != "Mechanical keyboard" {
+		t.Fatalf("expected the new name, got %q", updated.Name)
+	}
+	if !updated.CreatedAt.Equal(createdAt) || !updated.UpdatedAt.After(createdAt) {
+		t.Fatalf("expected created_at %v kept and updated_at moved, got %v and %v", createdAt, updated.CreatedAt, updated.UpdatedAt)
+	}
+}
+
+func testUpdateReturnsNotFound(t *testing.T, r repo.ProductRepository) {
+	now := time.Now()
+	err := r.Update(tenantContext(tenantA), &domain.Product{ID: 424242, Name: "Ghost", CreatedAt: &now})
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected gorm.ErrRecordNotFound, got %v", err)
+	}
+	if _, err := r.FindByID(tenantContext(tenantA), 424242); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected the missing product not to be created, got %v", err)
+	}
+}
+
+func testDelete(t *testing.T, r repo.ProductRepository) {
+	ctx := tenantContext(tenantA)
+	created := mustCreate(t, r, "Keyboard", nil)
+	for _, expected := range []int64{1, 0} {
+		deleted, err := r.Delete(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if deleted != expected {
+			t.Fatalf("expected %d deleted, got %d", expected, deleted)
+		}
+	}
+	if _, err := r.FindByID(ctx, created.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected gorm.ErrRecordNotFound after delete, got %v", err)
+	}
+}
+
+func testCreateBatchReportsEachItem(t *testing.T, r repo.ProductRepository) {
+	ctx := tenantContext(tenantA)
+	mustCreate(t, r, "Keyboard", sku("KB-01"))
+	products := []*domain.Product{
+		{Name: "Mouse", SKU: sku("MS-01")},
+		{Name: "Other keyboard", SKU: sku("KB-01")},
+		{Name: "Screen"},
+	}
+	results := r.CreateBatch(ctx, products)
+	if len(results) != len(products) {
+		t.Fatalf("expected %d results, got %d", len(products), len(results))
+	}
+	assertDuplicateSKU(t, results[1].Err)
+	for _, i := range []int{0, 2} {
+		if results[i].Err != nil || results[i].ID == 0 || results[i].ID != products[i].ID {
+			t.Fatalf("expected item %d to be created, got %+v", i, results[i])
+		}
+		found, err := r.FindByID(ctx, results[i].ID)
+		if err != nil || found.Name != products[i].Name || found.CreatedAt == nil {
+			t.Fatalf("expected item %d to be stored, got %+v, %v", i, found, err)
+		}
+	}
+}
+
+func testUpsertBySKU(t *testing.T, r repo.ProductRepository) {
+	ctx := tenantContext(tenantA)
+	existing := mustCreate(t, r, "Keyboard", sku("KB-01"))
+	stored, err := r.FindByID(ctx, existing.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	results := r.UpsertBySKU(ctx, []*domain.Product{
+		{Name: "Mechanical keyboard", SKU: sku("KB-01")},
+		{Name: "Mouse", SKU: sku("MS-01")},
+	})
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("expected item %d to succeed, got %v", i, result.Err)
+		}
+	}
+	if results[0].ID != existing.ID {
+		t.Fatalf("expected the existing id %d, got %d", existing.ID, results[0].ID)
+	}
+	updated, err := r.FindByID(ctx, existing.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if updated.Name != "Mechanical keyboard" || !updated.CreatedAt.Equal(*stored.CreatedAt) || !updated.UpdatedAt.After(*stored.UpdatedAt) {
+		t.Fatalf("expected the name and updated_at changed and created_at kept, got %+v", updated)
+	}
+	products, err := r.FindBySKUs(ctx, []string{"MS-01"})
+	if err != nil {
+		t.Fatalf("FindBySKUs: %v", err)
+	}
+	assertIDs(t, products, results[1].ID)
+}
+
+func testDeleteBatchReportsMissing(t *testing.T, r repo.ProductRepository) {
+	ctx := tenantContext(tenantA)
+	first := mustCreate(t, r, "Keyboard", nil)
+	second := mustCreate(t, r, "Mouse", nil)
+	results := r.DeleteBatch(ctx, []uint{first.ID, 424242, second.ID})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Fatalf("expected existing products to be deleted, got %+v", results)
+	}
+	if !errors.Is(results[1].Err, gorm.ErrRecordNotFound) || results[1].ID != 424242 {
+		t.Fatalf("expected gorm.ErrRecordNotFound for the missing id, got %+v", results[1])
+	}
+	products, err := r.FindByIDs(ctx, []uint{first.ID, second.ID})
+	if err != nil || len(products) != 0 {
+		t.Fatalf("expected no product left, got %v, %v", products, err)
+	}
+}
+
+func testTenantsCannotReadEachOther(t *testing.T, r repo.ProductRepository) {
+	ctxA, ctxB := tenantContext(tenantA), tenantContext(tenantB)
+	productA := mustCreateFor(t, r, tenantA, "Keyboard", sku("KB-01"))
+	// A sku is only unique within its tenant.
+	productB := mustCreateFor(t, r, tenantB, "Keyboard", sku("KB-01"))
+	if productA.TenantID != tenantA || productB.TenantID != tenantB {
+		t.Fatalf("expected products stamped with their tenant, got %q and %q", productA.TenantID, productB.TenantID)
+	}
+	for ctx, own := range map[context.Context]*domain.Product{ctxA: productA, ctxB: productB} {
+		other := productA
+		if own == productA {
+			other = productB
+		}
+		if _, err := r.FindByID(ctx, other.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expected the product of another tenant not to be found, got %v", err)
+		}
+		found, err := r.FindByID(ctx, own.ID)
+		if err != nil || found.TenantID != own.TenantID {
+			t.Fatalf("expected the own product of %s, got %+v, %v", own.TenantID, found, err)
+		}
+		products, err := r.FindByIDs(ctx, []uint{productA.ID, productB.ID})
+		if err != nil {
+			t.Fatalf("FindByIDs: %v", err)
+		}
+		assertIDs(t, products, own.ID)
+		products, err = r.FindBySKUs(ctx, []string{"KB-01"})
+		if err != nil {
+			t.Fatalf("FindBySKUs: %v", err)
+		}
+		assertIDs(t, products, own.ID)
+		products, err = r.FindRecentlyUpdated(ctx, 10)
+		if err != nil {
+			t.Fatalf("FindRecentlyUpdated: %v", err)
+		}
+		assertIDs(t, products, own.ID)
+		products, total, err := r.List(ctx, domain.ProductListQuery{Query: "keyboard", Page: 1, Size: 10})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if total != 1 {
+			t.Fatalf("expected 1 product listed for %s, got %d", own.TenantID, total)
+		}
+		assertIDs(t, products, own.ID)
+	}
+}
+
+func testTenantsCannotMutateEachOther(t *testing.T, r repo.ProductRepository) {
+	ctxB := tenantContext(tenantB)
+	productA := mustCreateFor(t, r, tenantA, "Keyboard", sku("KB-01"))
+	stolen := *productA
+	stolen.Name = "Stolen keyboard"
+	if err := r.Update(ctxB, &stolen); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected the update of another tenant's product to find nothing, got %v", err)
+	}
+	if deleted, err := r.Delete(ctxB, productA.ID); err != nil || deleted != 0 {
+		t.Fatalf("expected the delete of another tenant's product to delete nothing, got %d, %v", deleted, err)
+	}
+	results := r.DeleteBatch(ctxB, []uint{productA.ID})
+	if !errors.Is(results[0].Err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected the batch delete of another tenant's product to find nothing, got %+v", results[0])
+	}
+	results = r.UpsertBySKU(ctxB, []*domain.Product{{Name: "Other keyboard", SKU: sku("KB-01")}})
+	if results[0].Err != nil || results[0].ID == productA.ID {
+		t.Fatalf("expected the upsert to create a product of its own tenant, got %+v", results[0])
+	}
+	_, err := r.Create(ctxB, &domain.Product{TenantID: tenantA, Name: "Planted keyboard"})
+	if !errors.Is(err, db.ErrTenantMismatch) {
+		t.Fatalf("expected creating a product for another tenant to fail, got %v", err)
+	}
+	found, err := r.FindByID(tenantContext(tenantA), productA.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Name != "Keyboard" {
+		t.Fatalf("expected the product left untouched, got %q", found.Name)
+	}
+	_, total, err := r.List(tenantContext(tenantA), domain.ProductListQuery{Page: 1, Size: 10})
+	if err != nil || total != 1 {
+		t.Fatalf("expected tenant %s to keep its single product, got %d, %v", tenantA, total, err)
+	}
+}
+
+func testTenantIsRequired(t *testing.T, r repo.ProductRepository) {
+	ctx := context.Background()
+	productA := mustCreateFor(t, r, tenantA, "Keyboard", nil)
+	if _, err := r.Create(ctx, &domain.Product{Name: "Mouse"}); !errors.Is(err, db.ErrTenantRequired) {
+		t.Fatalf("expected Create without tenant to fail, got %v", err)
+	}
+	if _, err := r.FindByID(ctx, productA.ID); !errors.Is(err, db.ErrTenantRequired) {
+		t.Fatalf("expected FindByID without tenant to fail, got %v", err)
+	}
+	if _, _, err := r.List(ctx, domain.ProductListQuery{Page: 1, Size: 10}); !errors.Is(err, db.ErrTenantRequired) {
+		t.Fatalf("expected List without tenant to fail, got %v", err)
+	}
+	if _, err := r.Delete(ctx, productA.ID); !errors.Is(err, db.ErrTenantRequired) {
+		t.Fatalf("expected Delete without tenant to fail, got %v", err)
+	}
+}
+
+func testAllTenantsScopeSeesEveryTenant(t *testing.T, r repo.ProductRepository) {
+	productA := mustCreateFor(t, r, tenantA, "Keyboard", nil)
+	productB := mustCreateFor(t, r, tenantB, "Mouse", nil)
+	products, err := r.FindRecentlyUpdated(requestctx.WithAllTenants(context.Background()), 10)
+	if err != nil {
+		t.Fatalf("FindRecentlyUpdated: %v", err)
+	}
+	assertIDs(t, products, productA.ID, productB.ID)
+	for _, product := range products {
+		if product.TenantID == "" {
+			t.Fatalf("expected product %d to carry its tenant", product.ID)
+		}
+	}
+}
+
+func tenantContext(tenant string) context.Context {
+	return requestctx.WithTenant(context.Background(), tenant)
+}
+
+func mustCreate(t *testing.T, r repo.ProductRepository, name string, sku *string) *domain.Product {
+	t.Helper()
+	return mustCreateFor(t, r, tenantA, name, sku)
+}
+
+func mustCreateFor(t *testing.T, r repo.ProductRepository, tenant string, name string, sku *string) *domain.Product {
+	t.Helper()
+	product := &domain.Product{Name: name, SKU: sku}
+	if _, err := r.Create(tenantContext(tenant), product); err != nil {
+		t.Fatalf("Create %q: %v", name, err)
+	}
+	return product
+}
+
+func assertIDs(t *testing.T, products []domain.Product, ids ...uint) {
+	t.Helper()
+	expected := map[uint]bool{}
+	for _, id := range ids {
+		expected[id] = true
+	}
+	if len(products) != len(ids) {
+		t.Fatalf("expected products %v, got %d products", ids, len(products))
+	}
+	for _, product := range products {
+		if !expected[product.ID] {
+			t.Fatalf("expected products %v, got %d", ids, product.ID)
+		}
+	}
+}
+
+func assertDuplicateSKU(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, repo.ErrDuplicateSKU) {
+		t.Fatalf("expected repo.ErrDuplicateSKU, got %v", err)
+	}
+}
+
+func sku(value string) *string {
+	return &value
+}
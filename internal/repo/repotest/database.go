@@ -0,0 +1,80 @@
+package repotest
+
+import (
+	"context"
+	"os"
+	"sample-crud/infra/db"
+	"sample-crud/infra/migration"
+	"sample-crud/internal/config"
+	"testing"
+	"time"
+)
+
+// OpenPostgres connects to the database given by the TEST_DATABASE_*
+// variables, migrated, and skips the test or benchmark when TEST_DATABASE_HOST
+// is unset.
+func OpenPostgres(t testing.TB) *db.Resolver {
+	t.Helper()
+	host := os.Getenv("TEST_DATABASE_HOST")
+	if host == "" {
+		t.Skip("TEST_DATABASE_HOST not set")
+	}
+	return openMigrated(t, config.DatabaseConfig{
+		Driver:        db.DriverPostgres,
+		Host:          host,
+		Port:          getenv("TEST_DATABASE_PORT", "5432"),
+		User:          getenv("TEST_DATABASE_USER", "postgres"),
+		Password:      os.Getenv("TEST_DATABASE_PASSWORD"),
+		Name:          getenv("TEST_DATABASE_NAME", "postgres"),
+		SSLMode:       "disable",
+		MaxConnection: 10,
+		MaxIdle:       10,
+	})
+}
+
+// OpenSQLite opens a migrated in-memory database, private to the test.
+func OpenSQLite(t testing.TB) *db.Resolver {
+	t.Helper()
+	return openMigrated(t, config.DatabaseConfig{Driver: db.DriverSQLite, DSN: "file::memory:"})
+}
+
+// TruncateProducts empties the products of every tenant, for the contract to
+// start each case on an empty table.
+func TruncateProducts(t testing.TB, resolver *db.Resolver) {
+	t.Helper()
+	if err := resolver.Primary().Exec("TRUNCATE " + db.Schema + ".products RESTART IDENTITY").Error; err != nil {
+		t.Fatalf("truncate products: %v", err)
+	}
+}
+
+func openMigrated(t testing.TB, cfg config.DatabaseConfig) *db.Resolver {
+	t.Helper()
+	cfg.RetryMaxAttempts = 1
+	cfg.RetryBudget = 10
+	resolver, err := db.Open(cfg)
+	if err != nil {
+		t.Fatalf("db.Open: %v", err)
+	}
+	sqlDB, err := resolver.Primary().DB()
+	if err != nil {
+		t.Fatalf("DB: %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	migrator, err := migration.NewMigrator(sqlDB, cfg.Driver)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return resolver
+}
+
+func getenv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"sample-crud/infra/db"
+	"time"
+
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"gorm.io/gorm"
+)
+
+const txRetryBaseBackoff = time.Millisecond * 10
+
+type txKey struct{}
+
+// TransactionManager runs fn in a database transaction shared, through ctx,
+// with every repository call made inside it. Calls nested in an existing
+// transaction join it instead of opening a new one.
+type TransactionManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
+}
+
+type txOptions struct {
+	isolation sql.IsolationLevel
+	readOnly  bool
+	bulk      bool
+}
+
+type TxOption func(*txOptions)
+
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *txOptions) {
+		o.isolation = level
+	}
+}
+
+func WithReadOnly() TxOption {
+	return func(o *txOptions) {
+		o.readOnly = true
+	}
+}
+
+// WithBulk gives the transaction the bulk timeout instead of the write one.
+func WithBulk() TxOption {
+	return func(o *txOptions) {
+		o.bulk = true
+	}
+}
+
+type GormTransactionManager struct {
+	resolver   *db.Resolver
+	isolation  sql.IsolationLevel
+	maxRetries int
+}
+
+func (m GormTransactionManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	options := txOptions{isolation: m.isolation}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if !options.readOnly {
+		db.MarkWritten(ctx)
+	}
+	operation := db.OperationWrite
+	switch {
+	case options.bulk:
+		operation = db.OperationBulk
+	case options.readOnly:
+		operation = db.OperationRead
+	}
+	log := logger.GetLogger(ctx)
+	for attempt := 0; ; attempt++ {
+		err := m.attempt(ctx, fn, options, operation)
+		if err == nil || attempt >= m.maxRetries || !isSerializationFailure(err) {
+			return err
+		}
+		backoff := txRetryBaseBackoff << attempt
+		backoff += rand.N(backoff)
+		log.SWarn("Transaction aborted by a serialization failure, retrying in %v (attempt %d/%d)", backoff, attempt+1, m.maxRetries)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// attempt bounds the transaction with the timeout of operation, on the client
+// through its context and on the server through statement_timeout.
+func (m GormTransactionManager) attempt(ctx context.Context, fn func(ctx context.Context) error, options txOptions, operation db.Operation) error {
+	ctx, cancel := m.resolver.WithTimeout(ctx, operation)
+	defer cancel()
+	primary := m.resolver.Primary()
+	return primary.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if timeout := m.resolver.Timeout(operation); timeout > 0 && primary.Dialector.Name() == db.DriverPostgres {
+			if err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())).Error; err != nil {
+				return err
+			}
+		}
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	}, &sql.TxOptions{Isolation: options.isolation, ReadOnly: options.readOnly})
+}
+
+// isSerializationFailure leaves out connection errors: a transaction whose
+// commit was cut may have been applied, running it again is not safe.
+func isSerializationFailure(err error) bool {
+	class := db.Classify(err)
+	return class == db.ErrorSerialization || class == db.ErrorDeadlock
+}
+
+// reader returns the transaction carried by ctx, or a connection for a
+// read-only query when there is none.
+func reader(ctx context.Context, resolver *db.Resolver) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return resolver.Reader(ctx).WithContext(ctx)
+}
+
+// retry runs a read again after a transient error, unless it is part of a
+// transaction, which is then retried as a whole if at all. Writes are not
+// retried, see isSerializationFailure.
+func retry(ctx context.Context, resolver *db.Resolver, fn func() error) error {
+	if inTx(ctx) {
+		return fn()
+	}
+	return resolver.Retry(ctx, fn)
+}
+
+// writer returns the transaction carried by ctx, or the primary when there is
+// none.
+func writer(ctx context.Context, resolver *db.Resolver) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return resolver.Writer(ctx).WithContext(ctx)
+}
+
+func ParseIsolationLevel(level string) sql.IsolationLevel {
+	switch level {
+	case "read_uncommitted":
+		return sql.LevelReadUncommitted
+	case "read_committed":
+		return sql.LevelReadCommitted
+	case "repeatable_read":
+		return sql.LevelRepeatableRead
+	case "serializable":
+		return sql.LevelSerializable
+	default:
+		return sql.LevelDefault
+	}
+}
+
+func NewGormTransactionManager(resolver *db.Resolver, isolation sql.IsolationLevel, maxRetries int) *GormTransactionManager {
+	return &GormTransactionManager{
+		resolver:   resolver,
+		isolation:  isolation,
+		maxRetries: maxRetries,
+	}
+}
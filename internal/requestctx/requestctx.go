@@ -0,0 +1,61 @@
+package requestctx
+
+import (
+	"context"
+)
+
+const (
+	// ActorHeader and ActorMetadata name the actor of unauthenticated
+	// requests. They are client input and untrusted: the subject of a
+	// verified token takes their place, see tenant.Identity.
+	ActorHeader   = "X-Actor-ID"
+	ActorMetadata = "x-actor-id"
+
+	TenantHeader   = "X-Tenant-ID"
+	TenantMetadata = "x-tenant-id"
+
+	// traceIDKey is the key under which the common kit trace middleware and
+	// interceptor store the trace id.
+	traceIDKey = "trace_id"
+)
+
+type actorKey struct{}
+
+type tenantKey struct{}
+
+type allTenantsKey struct{}
+
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// WithAllTenants lifts the tenant scope of the queries made with ctx, for
+// background jobs working across tenants such as the outbox relay or the
+// cache warmup. It must never be used on the path of a request.
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+func AllTenants(ctx context.Context) bool {
+	all, _ := ctx.Value(allTenantsKey{}).(bool)
+	return all
+}
+
+func TraceID(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey).(string)
+	return traceID
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sample-crud/infra/cache"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/requestctx"
+	customerrors "sample-crud/pkg/errors"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type CacheAdminService interface {
+	InspectProduct(ctx context.Context, id uint) (*domain.CacheEntry, error)
+	EvictProduct(ctx context.Context, id uint) (*domain.CacheEviction, error)
+	EvictPattern(ctx context.Context, pattern string) (*domain.CacheEviction, error)
+	EvictTag(ctx context.Context, tag string) (*domain.CacheEviction, error)
+	FlushProductCache(ctx context.Context) (*domain.CacheEviction, error)
+}
+
+// productCachePatterns match the product entries, list pages and tag sets of
+// every tenant. Idempotency keys, import reports and tag versions share the
+// namespace but are not cache data.
+var productCachePatterns = []string{
+	cache.Namespace + ":*:product#*",
+	cache.Namespace + ":*:product_updated_at#*",
+	cache.Namespace + ":*:product_list#*",
+	cache.TagKey("*"),
+}
+
+type CacheAdminServiceImpl struct {
+	redisClient redis.UniversalClient
+}
+
+func (c CacheAdminServiceImpl) InspectProduct(ctx context.Context, id uint) (*domain.CacheEntry, error) {
+	log := logger.GetLogger(ctx)
+	cacheKey := getProductCacheKey(requestctx.Tenant(ctx), id)
+	log.SInfo("Inspecting cache entry %s", cacheKey)
+
+	pipe := c.redisClient.Pipeline()
+	value := pipe.Get(ctx, cacheKey)
+	ttl := pipe.TTL(ctx, cacheKey)
+	_, err := pipe.Exec(ctx)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Error("Fail to inspect cache entry", zap.Error(err))
+		return nil, err
+	}
+	entry := &domain.CacheEntry{Key: cacheKey}
+	if errors.Is(value.Err(), redis.Nil) {
+		return entry, nil
+	}
+	entry.Exists = true
+	entry.Value = value.Val()
+	entry.TTLSeconds = int64(ttl.Val().Seconds())
+	if ttl.Val() < 0 {
+		entry.TTLSeconds = -1
+	}
+	if _, schemaVersion, err := unmarshalProductCache(entry.Value); err == nil {
+		entry.SchemaVersion = schemaVersion
+	}
+	return entry, nil
+}
+
+func (c CacheAdminServiceImpl) EvictProduct(ctx context.Context, id uint) (*domain.CacheEviction, error) {
+	log := logger.GetLogger(ctx)
+	tenant := requestctx.Tenant(ctx)
+	cacheKey := getProductCacheKey(tenant, id)
+	log.SInfo("Evicting cache entry %s", cacheKey)
+	deleted, err := cache.DeleteKeys(ctx, c.redisClient, []string{cacheKey, getProductUpdatedAtCacheKey(tenant, id)})
+	if err != nil {
+		log.Error("Fail to evict cache entry", zap.Error(err))
+		return nil, err
+	}
+	return &domain.CacheEviction{Deleted: deleted}, nil
+}
+
+func (c CacheAdminServiceImpl) EvictPattern(ctx context.Context, pattern string) (*domain.CacheEviction, error) {
+	log := logger.GetLogger(ctx)
+	if !strings.HasPrefix(pattern, cache.Namespace+":") {
+		return nil, customerrors.NewBadRequestError("Invalid pattern", "pattern must start with "+cache.Namespace+":")
+	}
+	log.SInfo("Evicting cache entries matching %s", pattern)
+	deleted, err := cache.DeleteMatching(ctx, c.redisClient, pattern)
+	if err != nil {
+		log.Error("Fail to evict cache entries by pattern", zap.Error(err))
+		return nil, err
+	}
+	log.SInfo("Evicted %d cache entries matching %s", deleted, pattern)
+	return &domain.CacheEviction{Deleted: deleted}, nil
+}
+
+// EvictTag evicts the entries carrying tag, such as product:1 or list:all, in
+// the tenant of ctx.
+func (c CacheAdminServiceImpl) EvictTag(ctx context.Context, tag string) (*domain.CacheEviction, error) {
+	log := logger.GetLogger(ctx)
+	tag = requestctx.Tenant(ctx) + ":" + tag
+	log.SInfo("Evicting cache entries tagged %s", tag)
+	// A tag such as the list tag of a tenant can carry many entries.
+	deleted, err := cache.InvalidateTags(cache.Bulk(ctx), c.redisClient, tag)
+	if err != nil {
+		log.Error("Fail to evict cache entries by tag", zap.Error(err))
+		return nil, err
+	}
+	log.SInfo("Evicted %d cache entries tagged %s", deleted, tag)
+	return &domain.CacheEviction{Deleted: deleted}, nil
+}
+
+// FlushProductCache deletes the keys matching productCachePatterns, of every
+// tenant, and not the whole namespace.
+func (c CacheAdminServiceImpl) FlushProductCache(ctx context.Context) (*domain.CacheEviction, error) {
+	log := logger.GetLogger(ctx)
+	log.SInfo("Flushing the product cache")
+	var deleted int64
+	for _, pattern := range productCachePatterns {
+		n, err := cache.DeleteMatching(ctx, c.redisClient, pattern)
+		deleted += n
+		if err != nil {
+			log.Error("Fail to flush the product cache", zap.String("pattern", pattern), zap.Error(err))
+			return nil, err
+		}
+	}
+	log.SInfo("Flushed %d product cache entries", deleted)
+	return &domain.CacheEviction{Deleted: deleted}, nil
+}
+
+func NewCacheAdminService(redisClient redis.UniversalClient) *CacheAdminServiceImpl {
+	return &CacheAdminServiceImpl{redisClient: redisClient}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/repo"
+	customerrors "sample-crud/pkg/errors"
+	"sample-crud/pkg/response"
+
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// BatchCreateProducts returns one result per item, in order. Failed items do
+// not prevent the others from being created.
+func (p ProductServiceImpl) BatchCreateProducts(ctx context.Context, items []domain.ProductCreation) ([]domain.BatchItemResult, error) {
+	log := logger.GetLogger(ctx)
+	log.SInfo("Starting batch creation of %d products", len(items))
+	products := make([]*domain.Product, len(items))
+	var results []repo.BatchResult
+	err := p.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i, item := range items {
+			products[i] = &domain.Product{Name: item.Name}
+		}
+		results = p.productRepository.CreateBatch(ctx, products)
+		for i, result := range results {
+			if result.Err != nil {
+				continue
+			}
+			if err := p.appendHistory(ctx, domain.OperationCreate, result.ID, nil, products[i]); err != nil {
+				return err
+			}
+			if err := p.appendProductEvent(ctx, domain.EventProductCreated, result.ID, products[i].Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, repo.WithBulk())
+	if err != nil {
+		log.Error("Fail to create products in batch", zap.Error(err))
+		return nil, toServiceError(err)
+	}
+	invalidateListCache(ctx, p.redisClient, log)
+	return toBatchItemResults(ctx, results), nil
+}
+
+// BatchUpsertProducts creates the products whose sku is unknown and renames
+// the others.
+func (p ProductServiceImpl) BatchUpsertProducts(ctx context.Context, items []domain.ProductUpsert) ([]domain.BatchItemResult, error) {
+	log := logger.GetLogger(ctx)
+	log.SInfo("Starting batch upsert of %d products", len(items))
+	products := make([]*domain.Product, len(items))
+	var results []repo.BatchResult
+	err := p.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		skus := make([]string, len(items))
+		for i, item := range items {
+			sku := item.SKU
+			skus[i] = sku
+			products[i] = &domain.Product{Name: item.Name, SKU: &sku}
+		}
+		existingProducts, err := p.productRepository.FindBySKUs(ctx, skus)
+		if err != nil {
+			return err
+		}
+		existing := make(map[string]domain.Product, len(existingProducts))
+		for _, product := range existingProducts {
+			existing[*product.SKU] = product
+		}
+		results = p.productRepository.UpsertBySKU(ctx, products)
+		for i, result := range results {
+			if result.Err != nil {
+				continue
+			}
+			operation, eventType := domain.OperationCreate, domain.EventProductCreated
+			var before *domain.Product
+			if previous, ok := existing[skus[i]]; ok {
+				operation, eventType = domain.OperationUpdate, domain.EventProductUpdated
+				before = &previous
+				products[i].CreatedAt = previous.CreatedAt
+			}
+			if err := p.appendHistory(ctx, operation, result.ID, before, products[i]); err != nil {
+				return err
+			}
+			if err := p.appendProductEvent(ctx, eventType, result.ID, products[i].Name); err != nil {
+				return err
+			}
+			existing[skus[i]] = *products[i]
+		}
+		return nil
+	}, repo.WithBulk())
+	if err != nil {
+		log.Error("Fail to upsert products in batch", zap.Error(err))
+		return nil, toServiceError(err)
+	}
+	p.invalidateBatchCache(ctx, results, log)
+	return toBatchItemResults(ctx, results), nil
+}
+
+func (p ProductServiceImpl) BatchDeleteProducts(ctx context.Context, ids []uint) ([]domain.BatchItemResult, error) {
+	log := logger.GetLogger(ctx)
+	log.SInfo("Starting batch deletion of %d products", len(ids))
+	var results []repo.BatchResult
+	err := p.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existingProducts, err := p.productRepository.FindByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		existing := make(map[uint]*domain.Product, len(existingProducts))
+		for i := range existingProducts {
+			existing[existingProducts[i].ID] = &existingProducts[i]
+		}
+		results = p.productRepository.DeleteBatch(ctx, ids)
+		for _, result := range results {
+			before, ok := existing[result.ID]
+			if result.Err != nil || !ok {
+				continue
+			}
+			delete(existing, result.ID)
+			if err := p.appendHistory(ctx, domain.OperationDelete, result.ID, before, nil); err != nil {
+				return err
+			}
+			if err := p.appendProductEvent(ctx, domain.EventProductDeleted, result.ID, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, repo.WithBulk())
+	if err != nil {
+		log.Error("Fail to delete products in batch", zap.Error(err))
+		return nil, toServiceError(err)
+	}
+	p.invalidateBatchCache(ctx, results, log)
+	return toBatchItemResults(ctx, results), nil
+}
+
+func (p ProductServiceImpl) invalidateBatchCache(ctx context.Context, results []repo.BatchResult, log *logger.Logger) {
+	for _, result := range results {
+		if result.Err == nil {
+			invalidateProductCache(ctx, p.redisClient, result.ID, log)
+		}
+	}
+	invalidateListCache(ctx, p.redisClient, log)
+}
+
+func toBatchItemResults(ctx context.Context, results []repo.BatchResult) []domain.BatchItemResult {
+	items := make([]domain.BatchItemResult, len(results))
+	for i, result := range results {
+		items[i] = domain.BatchItemResult{Index: i, ID: result.ID, Success: result.Err == nil}
+		if result.Err != nil {
+			items[i].ErrorCode, items[i].Error = batchItemError(ctx, result.Err)
+		}
+	}
+	return items
+}
+
+func batchItemError(ctx context.Context, err error) (string, string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return customerrors.CodeNotFound, "Product not found"
+	case errors.Is(err, repo.ErrDuplicateSKU):
+		return customerrors.CodeBadRequest, "Sku already used by another product"
+	case errors.Is(err, repo.ErrConstraintViolation):
+		return customerrors.CodeBadRequest, "Integrity constraint violated"
+	default:
+		logger.GetLogger(ctx).Warn("Batch item failed", zap.Error(err))
+		return response.CodeInternalServerError, "INTERNAL_SERVER_ERROR"
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"sample-crud/infra/cache"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/repo"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	warmupBatchSize = 200
+	// warmupPipelineSize products make a pipeline of about 400 commands.
+	warmupPipelineSize = 50
+)
+
+type ProductCacheWarmer struct {
+	productRepository repo.ProductRepository
+	redisClient       redis.UniversalClient
+}
+
+// WarmRecent preloads the limit most recently updated products.
+func (w ProductCacheWarmer) WarmRecent(ctx context.Context, limit int) (int, error) {
+	log := logger.GetLogger(ctx)
+	log.SInfo("Starting cache warmup with %d most recently updated products", limit)
+	products, err := w.productRepository.FindRecentlyUpdated(ctx, limit)
+	if err != nil {
+		log.Error("Fail to load products for cache warmup", zap.Error(err))
+		return 0, err
+	}
+	return w.warm(ctx, products)
+}
+
+// WarmIDs preloads the given products, silently skipping ids that do not exist.
+func (w ProductCacheWarmer) WarmIDs(ctx context.Context, ids []uint) (int, error) {
+	log := logger.GetLogger(ctx)
+	log.SInfo("Starting cache warmup with %d product ids", len(ids))
+	warmed := 0
+	for start := 0; start < len(ids); start += warmupBatchSize {
+		end := min(start+warmupBatchSize, len(ids))
+		products, err := w.productRepository.FindByIDs(ctx, ids[start:end])
+		if err != nil {
+			log.Error("Fail to load products for cache warmup", zap.Error(err))
+			return warmed, err
+		}
+		n, err := w.warm(ctx, products)
+		warmed += n
+		if err != nil {
+			return warmed, err
+		}
+	}
+	return warmed, nil
+}
+
+// warm writes the products as a bulk operation, bounded by the deadline of ctx
+// rather than the Redis call timeout, so that a slow pipeline cannot open the
+// breaker and disable the cache.
+func (w ProductCacheWarmer) warm(ctx context.Context, products []domain.Product) (int, error) {
+	log := logger.GetLogger(ctx)
+	ctx = cache.Bulk(ctx)
+	warmed := 0
+	for start := 0; start < len(products); start += warmupPipelineSize {
+		end := min(start+warmupPipelineSize, len(products))
+		pipe := w.redisClient.Pipeline()
+		queued := 0
+		for i := start; i < end; i++ {
+			productJSON, err := marshalProductCache(&products[i])
+			if err != nil {
+				log.Warn("Fail to marshal product", zap.Error(err))
+				continue
+			}
+			pipeSetProductCache(ctx, pipe, &products[i], productJSON)
+			queued++
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Warn("Fail to save product cache during warmup", zap.Error(err))
+			return warmed, err
+		}
+		warmed += queued
+	}
+	log.SInfo("Cache warmup finished with %d products", warmed)
+	return warmed, nil
+}
+
+func NewProductCacheWarmer(productRepository repo.ProductRepository, redisClient redis.UniversalClient) *ProductCacheWarmer {
+	return &ProductCacheWarmer{
+		productRepository: productRepository,
+		redisClient:       redisClient,
+	}
+}
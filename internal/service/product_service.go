@@ -2,12 +2,18 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"sample-crud/infra/cache"
+	"sample-crud/infra/db"
 	"sample-crud/internal/domain"
 	"sample-crud/internal/repo"
+	"sample-crud/internal/requestctx"
 	customerrors "sample-crud/pkg/errors"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -19,23 +25,49 @@ import (
 type ProductService interface {
 	CreateProduct(ctx context.Context, name string) (uint, error)
 	FindByID(ctx context.Context, id uint) (*domain.ProductInfo, error)
+	FindByIDAsOf(ctx context.Context, id uint, asOf time.Time) (*domain.ProductInfo, error)
+	FindCachedUpdatedAt(ctx context.Context, id uint) (*time.Time, error)
+	ListProducts(ctx context.Context, query domain.ProductListQuery) (*domain.ProductPage, error)
 	UpdateProduct(ctx context.Context, id uint, name string) error
 	DeleteProduct(ctx context.Context, id uint) error
+	FindHistory(ctx context.Context, id uint, query domain.PageQuery) (*domain.ProductHistoryPage, error)
+	BatchCreateProducts(ctx context.Context, items []domain.ProductCreation) ([]domain.BatchItemResult, error)
+	BatchUpsertProducts(ctx context.Context, items []domain.ProductUpsert) ([]domain.BatchItemResult, error)
+	BatchDeleteProducts(ctx context.Context, ids []uint) ([]domain.BatchItemResult, error)
+	ExportProducts(ctx context.Context, query string, fn func(products []domain.ProductInfo) error) error
+	SaveImportReport(ctx context.Context, rejected []domain.ProductImportError) (string, error)
+	FindImportReport(ctx context.Context, reportID string) ([]domain.ProductImportError, error)
 }
 
 type ProductServiceImpl struct {
 	productRepository repo.ProductRepository
-	redisClient       *redis.Client
+	outboxRepository  repo.OutboxRepository
+	historyRepository repo.ProductHistoryRepository
+	txManager         repo.TransactionManager
+	redisClient       redis.UniversalClient
 }
 
 func (p ProductServiceImpl) CreateProduct(ctx context.Context, name string) (uint, error) {
 	log := logger.GetLogger(ctx)
 	log.SInfo("Starting product creation with name : %s", name)
-	id, err := p.productRepository.Create(ctx, &domain.Product{Name: name})
+	var id uint
+	err := p.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		product := &domain.Product{Name: name}
+		var err error
+		id, err = p.productRepository.Create(ctx, product)
+		if err != nil {
+			return err
+		}
+		if err := p.appendHistory(ctx, domain.OperationCreate, id, nil, product); err != nil {
+			return err
+		}
+		return p.appendProductEvent(ctx, domain.EventProductCreated, id, name)
+	})
 	if err != nil {
 		log.Error("Fail to create product", zap.Error(err))
-		return 0, err
+		return 0, toServiceError(err)
 	}
+	invalidateListCache(ctx, p.redisClient, log)
 	return id, nil
 }
 
@@ -43,20 +75,31 @@ func (p ProductServiceImpl) FindByID(ctx context.Context, id uint) (*domain.Prod
 	log := logger.GetLogger(ctx)
 	log.SInfo("Starting finding product with id : %d", id)
 
-	cacheKey := fmt.Sprintf("sample_crud:product#%d", id)
+	tenant := requestctx.Tenant(ctx)
+	cacheKey := getProductCacheKey(tenant, id)
 	cacheData, err := p.redisClient.Get(ctx, cacheKey).Result()
 	if err == nil {
 		log.SInfo("Product cache found product with id : %d", id)
-		var product domain.ProductInfo
-		if err := json.Unmarshal([]byte(cacheData), &product); err == nil {
-			return &product, nil
+		if product, schemaVersion, err := unmarshalProductCache(cacheData); err != nil {
+			log.SWarn("Unmarshal product cache failed")
+		} else if schemaVersion != productCacheSchemaVersion {
+			log.SInfo("Ignoring product cache with schema version %d", schemaVersion)
+		} else {
+			productInfo := domain.NewProductInfo(product)
+			return &productInfo, nil
 		}
-		log.SWarn("Unmarshal product cache failed")
 	} else if !errors.Is(err, redis.Nil) {
-		log.SWarn("Redis error : %v", err)
+		logCacheError(log, "Redis error", err)
 	}
 
 	log.SInfo("Product cache not found with id : %d", id)
+	// Read before loading the product, see cache.DropIfInvalidated.
+	var versions cache.TagVersions
+	if err == nil || errors.Is(err, redis.Nil) {
+		if versions, err = cache.ReadTagVersions(ctx, p.redisClient, productTag(tenant, id)); err != nil {
+			logCacheError(log, "Redis error", err)
+		}
+	}
 	product, err := p.productRepository.FindByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -64,35 +107,168 @@ func (p ProductServiceImpl) FindByID(ctx context.Context, id uint) (*domain.Prod
 			return nil, customerrors.NewNotFoundError("Product not found", err.Error())
 		}
 		log.Error("Fail to find product by id", zap.Error(err))
+		return nil, toServiceError(err)
+	}
+	productInfo := domain.NewProductInfo(product)
+	if versions != nil {
+		saveProductCache(ctx, p.redisClient, product, versions, log)
+	}
+	return &productInfo, nil
+}
+
+// FindCachedUpdatedAt reads the last update time of a product from its own
+// small cache entry, for conditional requests to be answered without loading
+// the product. It returns nil when the product is not cached, or the cache is
+// disabled.
+func (p ProductServiceImpl) FindCachedUpdatedAt(ctx context.Context, id uint) (*time.Time, error) {
+	value, err := p.redisClient.Get(ctx, getProductUpdatedAtCacheKey(requestctx.Tenant(ctx), id)).Result()
+	if errors.Is(err, redis.Nil) || errors.Is(err, cache.ErrCacheUnavailable) {
+		return nil, nil
+	}
+	if err != nil {
 		return nil, err
 	}
-	productInfo := domain.ProductInfo{
-		ID:   product.ID,
-		Name: product.Name,
+	updatedAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, err
 	}
-	saveProductCache(ctx, p.redisClient, product, log)
+	return &updatedAt, nil
+}
+
+// FindByIDAsOf rebuilds the product as it was at asOf from its history: the
+// state after the last write at or before asOf, or else the state before the
+// first write after it, for products older than their history. A product
+// without history is only known from its current row.
+func (p ProductServiceImpl) FindByIDAsOf(ctx context.Context, id uint, asOf time.Time) (*domain.ProductInfo, error) {
+	log := logger.GetLogger(ctx)
+	log.SInfo("Starting finding product with id : %d as of %s", id, asOf.Format(time.RFC3339Nano))
+	notFound := customerrors.NewNotFoundError("Product not found", fmt.Sprintf("product %d did not exist at %s", id, asOf.Format(time.RFC3339Nano)))
+
+	var snapshot *string
+	last, err := p.historyRepository.FindLastAt(ctx, id, asOf)
+	switch {
+	case err == nil:
+		snapshot = last.After
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		next, err := p.historyRepository.FindFirstAfter(ctx, id, asOf)
+		switch {
+		case err == nil:
+			snapshot = next.Before
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return p.findCurrentAsOf(ctx, id, asOf, notFound)
+		default:
+			log.Error("Fail to find product history", zap.Error(err))
+			return nil, toServiceError(err)
+		}
+	default:
+		log.Error("Fail to find product history", zap.Error(err))
+		return nil, toServiceError(err)
+	}
+	if snapshot == nil {
+		log.SInfo("Product with id : %d did not exist at that time", id)
+		return nil, notFound
+	}
+	var product domain.ProductSnapshot
+	if err := json.Unmarshal([]byte(*snapshot), &product); err != nil {
+		log.Error("Fail to unmarshal product snapshot", zap.Error(err))
+		return nil, err
+	}
+	productInfo := domain.NewProductInfo(&domain.Product{ID: product.ID, Name: product.Name, SKU: product.SKU})
 	return &productInfo, nil
 }
 
+func (p ProductServiceImpl) findCurrentAsOf(ctx context.Context, id uint, asOf time.Time, notFound error) (*domain.ProductInfo, error) {
+	product, err := p.productRepository.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, notFound
+	}
+	if err != nil {
+		logger.GetLogger(ctx).Error("Fail to find product by id", zap.Error(err))
+		return nil, toServiceError(err)
+	}
+	if product.CreatedAt == nil || product.CreatedAt.After(asOf) {
+		return nil, notFound
+	}
+	productInfo := domain.NewProductInfo(product)
+	return &productInfo, nil
+}
+
+func (p ProductServiceImpl) ListProducts(ctx context.Context, query domain.ProductListQuery) (*domain.ProductPage, error) {
+	log := logger.GetLogger(ctx)
+	log.SInfo("Starting listing products with query : %+v", query)
+
+	tenant := requestctx.Tenant(ctx)
+	cacheKey := getProductListCacheKey(tenant, query)
+	cacheData, err := p.redisClient.Get(ctx, cacheKey).Result()
+	if err == nil {
+		var page domain.ProductPage
+		if err := json.Unmarshal([]byte(cacheData), &page); err == nil {
+			log.SInfo("Product list cache found")
+			return &page, nil
+		}
+		log.SWarn("Unmarshal product list cache failed")
+	} else if !errors.Is(err, redis.Nil) {
+		logCacheError(log, "Redis error", err)
+	}
+
+	// Every write invalidates the list tag, see cache.DropIfInvalidated.
+	var versions cache.TagVersions
+	if err == nil || errors.Is(err, redis.Nil) {
+		if versions, err = cache.ReadTagVersions(ctx, p.redisClient, listTag(tenant)); err != nil {
+			logCacheError(log, "Redis error", err)
+		}
+	}
+	products, total, err := p.productRepository.List(ctx, query)
+	if err != nil {
+		log.Error("Fail to list products", zap.Error(err))
+		return nil, toServiceError(err)
+	}
+	page := domain.ProductPage{
+		Items: make([]domain.ProductInfo, 0, len(products)),
+		Page:  query.Page,
+		Size:  query.Size,
+		Total: total,
+	}
+	for i := range products {
+		page.Items = append(page.Items, domain.NewProductInfo(&products[i]))
+	}
+	if versions != nil {
+		saveProductListCache(ctx, p.redisClient, cacheKey, &page, versions, log)
+	}
+	return &page, nil
+}
+
 func (p ProductServiceImpl) UpdateProduct(ctx context.Context, id uint, name string) error {
 	log := logger.GetLogger(ctx)
 	log.SInfo("Starting product update with id : %d and name : %s", id, name)
 
-	existingProduct, err := p.productRepository.FindByID(ctx, id)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.SInfo("Record not found for product with id : %d", id)
-			return customerrors.NewNotFoundError("Product not found", err.Error())
+	// Repeatable read turns a concurrent update between the find and the save
+	// into a serialization failure, which the transaction manager retries.
+	err := p.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existingProduct, err := p.productRepository.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.SInfo("Record not found for product with id : %d", id)
+				return customerrors.NewNotFoundError("Product not found", err.Error())
+			}
+			log.Error("Fail to find product by id for update", zap.Error(err))
+			return err
 		}
-		log.Error("Fail to find product by id for update", zap.Error(err))
-		return err
-	}
 
-	existingProduct.Name = name
+		before := *existingProduct
+		existingProduct.Name = name
 
-	if err := p.productRepository.Update(ctx, existingProduct); err != nil {
-		log.Error("Fail to update product", zap.Error(err))
-		return err
+		if err := p.productRepository.Update(ctx, existingProduct); err != nil {
+			log.Error("Fail to update product", zap.Error(err))
+			return err
+		}
+		if err := p.appendHistory(ctx, domain.OperationUpdate, id, &before, existingProduct); err != nil {
+			return err
+		}
+		return p.appendProductEvent(ctx, domain.EventProductUpdated, id, name)
+	}, repo.WithIsolation(sql.LevelRepeatableRead))
+	if err != nil {
+		return toServiceError(err)
 	}
 
 	invalidateProductCache(ctx, p.redisClient, id, log)
@@ -105,15 +281,34 @@ func (p ProductServiceImpl) DeleteProduct(ctx context.Context, id uint) error {
 	log := logger.GetLogger(ctx)
 	log.SInfo("Starting product deletion with id : %d", id)
 
-	rowsAffected, err := p.productRepository.Delete(ctx, id)
-	if err != nil {
-		log.Error("Fail to delete product", zap.Error(err))
-		return err
-	}
+	err := p.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existingProduct, err := p.productRepository.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.SInfo("Record not found for product with id : %d", id)
+				return customerrors.NewNotFoundError("Product not found", err.Error())
+			}
+			log.Error("Fail to find product by id for deletion", zap.Error(err))
+			return err
+		}
+
+		rowsAffected, err := p.productRepository.Delete(ctx, id)
+		if err != nil {
+			log.Error("Fail to delete product", zap.Error(err))
+			return err
+		}
 
-	if rowsAffected == 0 {
-		log.SInfo("Record not found for product with id : %d", id)
-		return customerrors.NewNotFoundError("Product not found", "no rows affected")
+		if rowsAffected == 0 {
+			log.SInfo("Record not found for product with id : %d", id)
+			return customerrors.NewNotFoundError("Product not found", "no rows affected")
+		}
+		if err := p.appendHistory(ctx, domain.OperationDelete, id, existingProduct, nil); err != nil {
+			return err
+		}
+		return p.appendProductEvent(ctx, domain.EventProductDeleted, id, "")
+	})
+	if err != nil {
+		return toServiceError(err)
 	}
 
 	invalidateProductCache(ctx, p.redisClient, id, log)
@@ -122,35 +317,267 @@ func (p ProductServiceImpl) DeleteProduct(ctx context.Context, id uint) error {
 	return nil
 }
 
-func getProductCacheKey(id uint) string {
-	return fmt.Sprintf("sample_crud:product#%d", id)
+func (p ProductServiceImpl) FindHistory(ctx context.Context, id uint, query domain.PageQuery) (*domain.ProductHistoryPage, error) {
+	log := logger.GetLogger(ctx)
+	log.SInfo("Starting finding history of product with id : %d", id)
+	history, total, err := p.historyRepository.ListByProductID(ctx, id, query)
+	if err != nil {
+		log.Error("Fail to find product history", zap.Error(err))
+		return nil, toServiceError(err)
+	}
+	page := domain.ProductHistoryPage{
+		Items: make([]domain.ProductHistoryInfo, 0, len(history)),
+		Page:  query.Page,
+		Size:  query.Size,
+		Total: total,
+	}
+	for _, h := range history {
+		info := domain.ProductHistoryInfo{
+			ID:        h.ID,
+			ProductID: h.ProductID,
+			Operation: h.Operation,
+			Actor:     h.Actor,
+			TraceID:   h.TraceID,
+			CreatedAt: h.CreatedAt,
+		}
+		if h.Before != nil {
+			info.Before = json.RawMessage(*h.Before)
+		}
+		if h.After != nil {
+			info.After = json.RawMessage(*h.After)
+		}
+		page.Items = append(page.Items, info)
+	}
+	return &page, nil
+}
+
+// appendHistory, like appendProductEvent, must run inside the transaction of
+// the write it records.
+func (p ProductServiceImpl) appendHistory(ctx context.Context, operation string, id uint, before *domain.Product, after *domain.Product) error {
+	history := &domain.ProductHistory{
+		ProductID: id,
+		Operation: operation,
+		Actor:     requestctx.Actor(ctx),
+		TraceID:   requestctx.TraceID(ctx),
+	}
+	var err error
+	if history.Before, err = marshalSnapshot(before); err != nil {
+		return err
+	}
+	if history.After, err = marshalSnapshot(after); err != nil {
+		return err
+	}
+	if err := p.historyRepository.Append(ctx, history); err != nil {
+		logger.GetLogger(ctx).Error("Fail to append product history", zap.String("operation", operation), zap.Error(err))
+		return err
+	}
+	return nil
 }
 
-func saveProductCache(ctx context.Context, redisClient *redis.Client, product *domain.Product, log *logger.Logger) {
-	productJSON, err := json.Marshal(product)
+func marshalSnapshot(product *domain.Product) (*string, error) {
+	if product == nil {
+		return nil, nil
+	}
+	snapshot, err := json.Marshal(domain.NewProductSnapshot(product))
+	if err != nil {
+		return nil, err
+	}
+	value := string(snapshot)
+	return &value, nil
+}
+
+// appendProductEvent must run inside the transaction of the write it records,
+// so that the event is stored if and only if the write is committed.
+func (p ProductServiceImpl) appendProductEvent(ctx context.Context, eventType string, id uint, name string) error {
+	payload, err := json.Marshal(domain.ProductEvent{ID: id, TenantID: requestctx.Tenant(ctx), Name: name, OccurredAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	err = p.outboxRepository.Append(ctx, &domain.OutboxEvent{
+		AggregateType: domain.AggregateProduct,
+		AggregateID:   strconv.FormatUint(uint64(id), 10),
+		EventType:     eventType,
+		Payload:       string(payload),
+	})
+	if err != nil {
+		logger.GetLogger(ctx).Error("Fail to append outbox event", zap.String("event_type", eventType), zap.Error(err))
+	}
+	return err
+}
+
+const (
+	// productCacheSchemaVersion is stored with every cached product and in the
+	// list page keys, bump it whenever the cached payload changes shape so
+	// that entries written by older versions are read as misses.
+	productCacheSchemaVersion = 1
+	productCacheTTL           = time.Minute * 30
+	productListCacheTTL       = time.Minute * 5
+	listAllTag                = "list:all"
+)
+
+// Cache keys and tags are namespaced by tenant, product ids are unique across
+// tenants but a list page or a tag is not.
+func getProductCacheKey(tenant string, id uint) string {
+	return fmt.Sprintf("sample_crud:%s:product#%d", tenant, id)
+}
+
+func getProductUpdatedAtCacheKey(tenant string, id uint) string {
+	return fmt.Sprintf("sample_crud:%s:product_updated_at#%d", tenant, id)
+}
+
+func getProductListCacheKey(tenant string, query domain.ProductListQuery) string {
+	params := url.Values{}
+	params.Set("q", query.Query)
+	params.Set("page", strconv.Itoa(query.Page))
+	params.Set("size", strconv.Itoa(query.Size))
+	params.Set("v", strconv.Itoa(productCacheSchemaVersion))
+	return "sample_crud:" + tenant + ":product_list#" + params.Encode()
+}
+
+// cachedProduct is the payload of a product cache entry.
+type cachedProduct struct {
+	SchemaVersion int             `json:"schema_version"`
+	Product       *domain.Product `json:"product"`
+}
+
+func marshalProductCache(product *domain.Product) (string, error) {
+	data, err := json.Marshal(cachedProduct{SchemaVersion: productCacheSchemaVersion, Product: product})
+	return string(data), err
+}
+
+// unmarshalProductCache returns the cached product with the schema version it
+// was written with, entries without one predate versioning and report 0.
+func unmarshalProductCache(data string) (*domain.Product, int, error) {
+	var cached cachedProduct
+	if err := json.Unmarshal([]byte(data), &cached); err != nil {
+		return nil, 0, err
+	}
+	return cached.Product, cached.SchemaVersion, nil
+}
+
+func productTag(tenant string, id uint) string {
+	return fmt.Sprintf("%s:product:%d", tenant, id)
+}
+
+func listTag(tenant string) string {
+	return tenant + ":" + listAllTag
+}
+
+// saveProductCache caches a product loaded after versions were read, unless
+// the product was invalidated meanwhile.
+func saveProductCache(ctx context.Context, redisClient redis.UniversalClient, product *domain.Product, versions cache.TagVersions, log *logger.Logger) {
+	productJSON, err := marshalProductCache(product)
 	if err != nil {
 		log.Warn("Fail to marshal product", zap.Error(err))
 		return
 	}
-	err = redisClient.Set(ctx, getProductCacheKey(product.ID), string(productJSON), time.Minute*30).Err()
+	_, err = redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipeSetProductCache(ctx, pipe, product, productJSON)
+		return nil
+	})
 	if err != nil {
-		log.Warn("Fail to save product cache", zap.Error(err))
+		logCacheError(log, "Fail to save product cache", err)
 		return
 	}
-	log.SInfo("Product cache saved successfully")
+	kept, err := cache.DropIfInvalidated(ctx, redisClient, versions,
+		getProductCacheKey(product.TenantID, product.ID), getProductUpdatedAtCacheKey(product.TenantID, product.ID))
+	switch {
+	case err != nil:
+		logCacheError(log, "Fail to check product cache invalidation", err)
+	case !kept:
+		log.SInfo("Product cache dropped, the product was invalidated while loading")
+	default:
+		log.SInfo("Product cache saved successfully")
+	}
+}
+
+// pipeSetProductCache queues the product entry and its update time entry,
+// both under the product tag so that they are invalidated together.
+func pipeSetProductCache(ctx context.Context, pipe redis.Pipeliner, product *domain.Product, productJSON string) {
+	tag := productTag(product.TenantID, product.ID)
+	cache.PipeSetWithTags(ctx, pipe, getProductCacheKey(product.TenantID, product.ID), productJSON, productCacheTTL, tag)
+	if product.UpdatedAt != nil {
+		cache.PipeSetWithTags(ctx, pipe, getProductUpdatedAtCacheKey(product.TenantID, product.ID), product.UpdatedAt.Format(time.RFC3339Nano), productCacheTTL, tag)
+	}
 }
 
-func invalidateProductCache(ctx context.Context, redisClient *redis.Client, id uint, log *logger.Logger) {
-	cacheKey := getProductCacheKey(id)
-	err := redisClient.Del(ctx, cacheKey).Err()
+func saveProductListCache(ctx context.Context, redisClient redis.UniversalClient, cacheKey string, page *domain.ProductPage, versions cache.TagVersions, log *logger.Logger) {
+	pageJSON, err := json.Marshal(page)
 	if err != nil {
+		log.Warn("Fail to marshal product list", zap.Error(err))
+		return
+	}
+	tenant := requestctx.Tenant(ctx)
+	tags := make([]string, 0, len(page.Items)+1)
+	tags = append(tags, listTag(tenant))
+	for _, item := range page.Items {
+		tags = append(tags, productTag(tenant, item.ID))
+	}
+	if err := cache.SetWithTags(ctx, redisClient, cacheKey, string(pageJSON), productListCacheTTL, tags...); err != nil {
+		logCacheError(log, "Fail to save product list cache", err)
+		return
+	}
+	if _, err := cache.DropIfInvalidated(ctx, redisClient, versions, cacheKey); err != nil {
+		logCacheError(log, "Fail to check product list cache invalidation", err)
+	}
+}
+
+// logCacheError logs a failed cache read or save, at debug level while the
+// breaker is open since the outage was reported when it opened and every
+// request would report it again.
+func logCacheError(log *logger.Logger, msg string, err error) {
+	if errors.Is(err, cache.ErrCacheUnavailable) {
+		log.Debug(msg, zap.Error(err))
+		return
+	}
+	log.Warn(msg, zap.Error(err))
+}
+
+// invalidateProductCache drops the product entry and every cached list page of
+// the tenant, a write can change the content, the order or the total of any
+// page.
+func invalidateProductCache(ctx context.Context, redisClient redis.UniversalClient, id uint, log *logger.Logger) {
+	tenant := requestctx.Tenant(ctx)
+	if _, err := cache.InvalidateTags(ctx, redisClient, productTag(tenant, id), listTag(tenant)); err != nil {
+		log.Warn("Fail to invalidate product cache", zap.Error(err))
+	}
+	if _, err := cache.DeleteKeys(ctx, redisClient, []string{getProductCacheKey(tenant, id), getProductUpdatedAtCacheKey(tenant, id)}); err != nil {
 		log.Warn("Fail to delete product cache", zap.Error(err))
 	}
 }
 
-func NewProductService(productRepository repo.ProductRepository, redisClient *redis.Client) *ProductServiceImpl {
+func invalidateListCache(ctx context.Context, redisClient redis.UniversalClient, log *logger.Logger) {
+	if _, err := cache.InvalidateTags(ctx, redisClient, listTag(requestctx.Tenant(ctx))); err != nil {
+		log.Warn("Fail to invalidate product list cache", zap.Error(err))
+	}
+}
+
+// toServiceError reports database timeouts as such rather than as internal
+// errors, other errors are returned unchanged.
+func toServiceError(err error) error {
+	switch {
+	case db.Classify(err) == db.ErrorTimeout:
+		return customerrors.NewTimeoutError("Database operation timed out", err.Error())
+	case errors.Is(err, repo.ErrDuplicateSKU):
+		return customerrors.NewBadRequestError("Sku already used by another product", err.Error())
+	case errors.Is(err, repo.ErrConstraintViolation):
+		return customerrors.NewBadRequestError("Integrity constraint violated", err.Error())
+	}
+	return err
+}
+
+func NewProductService(
+	productRepository repo.ProductRepository,
+	outboxRepository repo.OutboxRepository,
+	historyRepository repo.ProductHistoryRepository,
+	txManager repo.TransactionManager,
+	redisClient redis.UniversalClient,
+) *ProductServiceImpl {
 	return &ProductServiceImpl{
 		productRepository: productRepository,
+		outboxRepository:  outboxRepository,
+		historyRepository: historyRepository,
+		txManager:         txManager,
 		redisClient:       redisClient,
 	}
 }
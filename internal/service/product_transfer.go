@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sample-crud/infra/cache"
+	"sample-crud/internal/domain"
+	"sample-crud/internal/requestctx"
+	customerrors "sample-crud/pkg/errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tee-nullpointer/go-common-kit/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	exportPageSize  = 500
+	importReportTTL = time.Hour * 24
+)
+
+// ExportProducts hands the products whose name contains query to fn one page
+// at a time, reading past the cache. Pages are read by id, so that products
+// written during the export are neither skipped nor repeated.
+func (p ProductServiceImpl) ExportProducts(ctx context.Context, query string, fn func(products []domain.ProductInfo) error) error {
+	log := logger.GetLogger(ctx)
+	log.SInfo("Starting product export with query : %s", query)
+	exported := 0
+	var afterID uint
+	for {
+		products, err := p.productRepository.ListAfter(ctx, query, afterID, exportPageSize)
+		if err != nil {
+			log.Error("Fail to export products", zap.Uint("after_id", afterID), zap.Error(err))
+			return toServiceError(err)
+		}
+		if len(products) > 0 {
+			afterID = products[len(products)-1].ID
+			items := make([]domain.ProductInfo, len(products))
+			for i := range products {
+				items[i] = domain.NewProductInfo(&products[i])
+			}
+			if err := fn(items); err != nil {
+				return err
+			}
+			exported += len(items)
+		}
+		if len(products) < exportPageSize {
+			log.SInfo("Product export finished with %d products", exported)
+			return nil
+		}
+	}
+}
+
+// SaveImportReport keeps the rejected rows of an import for importReportTTL
+// and returns the id to download them with. A report may hold every row of a
+// large file, so it is written as a bulk operation, bounded by the deadline of
+// ctx rather than the Redis call timeout.
+func (p ProductServiceImpl) SaveImportReport(ctx context.Context, rejected []domain.ProductImportError) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	reportID := hex.EncodeToString(id)
+	reportJSON, err := json.Marshal(rejected)
+	if err != nil {
+		return "", err
+	}
+	if err := p.redisClient.Set(cache.Bulk(ctx), getImportReportKey(requestctx.Tenant(ctx), reportID), reportJSON, importReportTTL).Err(); err != nil {
+		logger.GetLogger(ctx).Warn("Fail to save product import report", zap.Error(err))
+		return "", err
+	}
+	return reportID, nil
+}
+
+// FindImportReport reads a report saved by SaveImportReport, as a bulk
+// operation for the same reason.
+func (p ProductServiceImpl) FindImportReport(ctx context.Context, reportID string) ([]domain.ProductImportError, error) {
+	reportJSON, err := p.redisClient.Get(cache.Bulk(ctx), getImportReportKey(requestctx.Tenant(ctx), reportID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, customerrors.NewNotFoundError("Import report not found", fmt.Sprintf("import report %s does not exist or has expired", reportID))
+	}
+	if err != nil {
+		logger.GetLogger(ctx).Error("Fail to read product import report", zap.Error(err))
+		return nil, err
+	}
+	var rejected []domain.ProductImportError
+	if err := json.Unmarshal(reportJSON, &rejected); err != nil {
+		return nil, err
+	}
+	return rejected, nil
+}
+
+func getImportReportKey(tenant string, reportID string) string {
+	return fmt.Sprintf("sample_crud:%s:product_import_report#%s", tenant, reportID)
+}
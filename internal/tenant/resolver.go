@@ -0,0 +1,90 @@
+package tenant
+
+import (
+	"fmt"
+	"regexp"
+	"sample-crud/internal/config"
+	customerrors "sample-crud/pkg/errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// validTenant keeps tenant ids safe to embed in cache keys and tags.
+var validTenant = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Identity is who a request comes from: its tenant, and the subject of its
+// bearer token when the token was verified.
+type Identity struct {
+	Tenant  string
+	Subject string
+}
+
+// Resolver finds the tenant of a request in the claim of its bearer token when
+// a secret is configured, otherwise in its tenant header or metadata, then
+// falls back to the default unless a tenant is required.
+type Resolver struct {
+	secret   []byte
+	claim    string
+	fallback string
+}
+
+// Resolve takes the tenant header or metadata value and the authorization
+// one. When a secret is configured a valid token is required, the header only
+// being checked against it, otherwise the header is trusted.
+func (r Resolver) Resolve(header string, authorization string) (Identity, *customerrors.CustomError) {
+	tenant := r.fallback
+	var subject string
+	if len(r.secret) > 0 {
+		token, found := strings.CutPrefix(authorization, "Bearer ")
+		if !found {
+			return Identity{}, customerrors.NewUnauthorizedError("Token required", "missing bearer token")
+		}
+		claimed, sub, err := r.parse(token)
+		if err != nil {
+			return Identity{}, customerrors.NewUnauthorizedError("Invalid token", err.Error())
+		}
+		if header != "" && header != claimed {
+			return Identity{}, customerrors.NewUnauthorizedError("Tenant mismatch", "tenant header does not match the token")
+		}
+		tenant, subject = claimed, sub
+	} else if header != "" {
+		tenant = header
+	}
+	if tenant == "" {
+		return Identity{}, customerrors.NewBadRequestError("Tenant required", "missing tenant header or token claim")
+	}
+	if !validTenant.MatchString(tenant) {
+		return Identity{}, customerrors.NewBadRequestError("Invalid tenant", "tenant must be 1 to 64 letters, digits, '-' or '_'")
+	}
+	return Identity{Tenant: tenant, Subject: subject}, nil
+}
+
+// parse returns the tenant claim of a verified token, and its sub claim.
+func (r Resolver) parse(token string) (string, string, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return r.secret, nil
+	}, jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}))
+	if err != nil {
+		return "", "", err
+	}
+	tenant, ok := claims[r.claim].(string)
+	if !ok || tenant == "" {
+		return "", "", fmt.Errorf("token has no %s claim", r.claim)
+	}
+	subject, _ := claims["sub"].(string)
+	return tenant, subject, nil
+}
+
+func NewResolver(cfg config.TenantConfig) *Resolver {
+	resolver := &Resolver{
+		secret:   []byte(cfg.JWTSecret),
+		claim:    cfg.JWTClaim,
+		fallback: cfg.Default,
+	}
+	if cfg.Required {
+		resolver.fallback = ""
+	}
+	return resolver
+}
@@ -8,8 +8,12 @@ import (
 )
 
 const (
-	CodeBadRequest = "40"
-	CodeNotFound   = "44"
+	CodeBadRequest    = "40"
+	CodeUnauthorized  = "41"
+	CodeUnprocessable = "42"
+	CodeNotFound      = "44"
+	CodeConflict      = "49"
+	CodeTimeout       = "54"
 )
 
 type CustomError struct {
@@ -41,6 +45,22 @@ func NewBadRequestError(message string, detail string) *CustomError {
 	return NewCustomError(http.StatusBadRequest, codes.InvalidArgument, CodeBadRequest, message, detail)
 }
 
+func NewUnauthorizedError(message string, detail string) *CustomError {
+	return NewCustomError(http.StatusUnauthorized, codes.Unauthenticated, CodeUnauthorized, message, detail)
+}
+
 func NewNotFoundError(message string, detail string) *CustomError {
 	return NewCustomError(http.StatusNotFound, codes.NotFound, CodeNotFound, message, detail)
 }
+
+func NewConflictError(message string, detail string) *CustomError {
+	return NewCustomError(http.StatusConflict, codes.Aborted, CodeConflict, message, detail)
+}
+
+func NewUnprocessableError(message string, detail string) *CustomError {
+	return NewCustomError(http.StatusUnprocessableEntity, codes.FailedPrecondition, CodeUnprocessable, message, detail)
+}
+
+func NewTimeoutError(message string, detail string) *CustomError {
+	return NewCustomError(http.StatusGatewayTimeout, codes.DeadlineExceeded, CodeTimeout, message, detail)
+}
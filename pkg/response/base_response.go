@@ -2,6 +2,7 @@ package response
 
 const (
 	CodeSuccess             = "00"
+	CodePartialSuccess      = "01"
 	CodeInternalServerError = "50"
 )
 
@@ -30,6 +31,19 @@ func Success(data interface{}) BaseResponse {
 	}
 }
 
+// Batch answers with CodePartialSuccess as soon as one item failed, the outcome
+// of every item being in the data.
+func Batch(result interface{}, failed int) BaseResponse {
+	if failed == 0 {
+		return Success(result)
+	}
+	return BaseResponse{
+		ResponseCode:    CodePartialSuccess,
+		ResponseMessage: "Partial success",
+		Data:            result,
+	}
+}
+
 func NewCreatedData(id uint) CreatedData {
 	return CreatedData{
 		ID: id,
@@ -0,0 +1,33 @@
+package response
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"google.golang.org/protobuf/proto"
+)
+
+// Formats offered to the Accept header, JSON first so that it stays the
+// default when the client accepts anything.
+var Formats = []string{binding.MIMEJSON, binding.MIMEPROTOBUF, binding.MIMEMSGPACK2, binding.MIMEMSGPACK}
+
+// Render writes body in the format negotiated from the Accept header, which
+// Vary tells caches. message builds its protobuf form, only called when
+// protobuf is negotiated; when it fails, the error is left to the error
+// middleware.
+func Render(c *gin.Context, status int, body BaseResponse, message func() (proto.Message, error)) {
+	c.Header("Vary", "Accept")
+	switch c.NegotiateFormat(Formats...) {
+	case binding.MIMEPROTOBUF:
+		m, err := message()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.ProtoBuf(status, m)
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		c.Render(status, render.MsgPack{Data: body})
+	default:
+		c.JSON(status, body)
+	}
+}
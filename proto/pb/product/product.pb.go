@@ -9,6 +9,7 @@ package product
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,8 +23,10 @@ const (
 )
 
 type GetProductRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// when set, the product as it was at that time
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,10 +68,18 @@ func (x *GetProductRequest) GetId() uint64 {
 	return 0
 }
 
+func (x *GetProductRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
 type GetProductResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Sku           string                 `protobuf:"bytes,3,opt,name=sku,proto3" json:"sku,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -117,51 +128,1239 @@ func (x *GetProductResponse) GetName() string {
 	return ""
 }
 
-var File_product_proto protoreflect.FileDescriptor
+func (x *GetProductResponse) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
 
-const file_product_proto_rawDesc = "" +
-	"\n" +
-	"\rproduct.proto\x12\rproto.product\"#\n" +
-	"\x11GetProductRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x04R\x02id\"8\n" +
-	"\x12GetProductResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name2e\n" +
-	"\x0eProductService\x12S\n" +
-	"\n" +
-	"GetProduct\x12 .proto.product.GetProductRequest\x1a!.proto.product.GetProductResponse\"\x00B\x0eZ\f./pb/productb\x06proto3"
+type ListProductHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Size          int32                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-var (
-	file_product_proto_rawDescOnce sync.Once
-	file_product_proto_rawDescData []byte
-)
+func (x *ListProductHistoryRequest) Reset() {
+	*x = ListProductHistoryRequest{}
+	mi := &file_product_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
 
-func file_product_proto_rawDescGZIP() []byte {
-	file_product_proto_rawDescOnce.Do(func() {
-		file_product_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_product_proto_rawDesc), len(file_product_proto_rawDesc)))
-	})
-	return file_product_proto_rawDescData
+func (x *ListProductHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_product_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_product_proto_goTypes = []any{
-	(*GetProductRequest)(nil),  // 0: proto.product.GetProductRequest
-	(*GetProductResponse)(nil), // 1: proto.product.GetProductResponse
+func (*ListProductHistoryRequest) ProtoMessage() {}
+
+func (x *ListProductHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
-var file_product_proto_depIdxs = []int32{
-	0, // 0: proto.product.ProductService.GetProduct:input_type -> proto.product.GetProductRequest
-	1, // 1: proto.product.ProductService.GetProduct:output_type -> proto.product.GetProductResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+// Deprecated: Use ListProductHistoryRequest.ProtoReflect.Descriptor instead.
+func (*ListProductHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{2}
 }
 
-func init() { file_product_proto_init() }
-func file_product_proto_init() {
-	if File_product_proto != nil {
-		return
+func (x *ListProductHistoryRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ListProductHistoryRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListProductHistoryRequest) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type ProductHistoryEntry struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId uint64                 `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Operation string                 `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"`
+	// JSON snapshots of the product, empty when there is none
+	Before        string                 `protobuf:"bytes,4,opt,name=before,proto3" json:"before,omitempty"`
+	After         string                 `protobuf:"bytes,5,opt,name=after,proto3" json:"after,omitempty"`
+	Actor         string                 `protobuf:"bytes,6,opt,name=actor,proto3" json:"actor,omitempty"`
+	TraceId       string                 `protobuf:"bytes,7,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductHistoryEntry) Reset() {
+	*x = ProductHistoryEntry{}
+	mi := &file_product_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductHistoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductHistoryEntry) ProtoMessage() {}
+
+func (x *ProductHistoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductHistoryEntry.ProtoReflect.Descriptor instead.
+func (*ProductHistoryEntry) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProductHistoryEntry) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ProductHistoryEntry) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ProductHistoryEntry) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *ProductHistoryEntry) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *ProductHistoryEntry) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+func (x *ProductHistoryEntry) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ProductHistoryEntry) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *ProductHistoryEntry) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListProductHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*ProductHistoryEntry `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Size          int32                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Total         int64                  `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductHistoryResponse) Reset() {
+	*x = ListProductHistoryResponse{}
+	mi := &file_product_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductHistoryResponse) ProtoMessage() {}
+
+func (x *ListProductHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductHistoryResponse.ProtoReflect.Descriptor instead.
+func (*ListProductHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListProductHistoryResponse) GetItems() []*ProductHistoryEntry {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ListProductHistoryResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListProductHistoryResponse) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ListProductHistoryResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type BaseResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ResponseCode    string                 `protobuf:"bytes,1,opt,name=response_code,json=responseCode,proto3" json:"response_code,omitempty"`
+	ResponseMessage string                 `protobuf:"bytes,2,opt,name=response_message,json=responseMessage,proto3" json:"response_message,omitempty"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*BaseResponse_Created
+	//	*BaseResponse_Product
+	//	*BaseResponse_Products
+	//	*BaseResponse_History
+	//	*BaseResponse_Batch
+	//	*BaseResponse_ImportResult
+	Data          isBaseResponse_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BaseResponse) Reset() {
+	*x = BaseResponse{}
+	mi := &file_product_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BaseResponse) ProtoMessage() {}
+
+func (x *BaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BaseResponse.ProtoReflect.Descriptor instead.
+func (*BaseResponse) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BaseResponse) GetResponseCode() string {
+	if x != nil {
+		return x.ResponseCode
+	}
+	return ""
+}
+
+func (x *BaseResponse) GetResponseMessage() string {
+	if x != nil {
+		return x.ResponseMessage
+	}
+	return ""
+}
+
+func (x *BaseResponse) GetData() isBaseResponse_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *BaseResponse) GetCreated() *CreatedData {
+	if x != nil {
+		if x, ok := x.Data.(*BaseResponse_Created); ok {
+			return x.Created
+		}
+	}
+	return nil
+}
+
+func (x *BaseResponse) GetProduct() *GetProductResponse {
+	if x != nil {
+		if x, ok := x.Data.(*BaseResponse_Product); ok {
+			return x.Product
+		}
+	}
+	return nil
+}
+
+func (x *BaseResponse) GetProducts() *ProductPage {
+	if x != nil {
+		if x, ok := x.Data.(*BaseResponse_Products); ok {
+			return x.Products
+		}
+	}
+	return nil
+}
+
+func (x *BaseResponse) GetHistory() *ListProductHistoryResponse {
+	if x != nil {
+		if x, ok := x.Data.(*BaseResponse_History); ok {
+			return x.History
+		}
+	}
+	return nil
+}
+
+func (x *BaseResponse) GetBatch() *BatchResult {
+	if x != nil {
+		if x, ok := x.Data.(*BaseResponse_Batch); ok {
+			return x.Batch
+		}
+	}
+	return nil
+}
+
+func (x *BaseResponse) GetImportResult() *ProductImportResult {
+	if x != nil {
+		if x, ok := x.Data.(*BaseResponse_ImportResult); ok {
+			return x.ImportResult
+		}
+	}
+	return nil
+}
+
+type isBaseResponse_Data interface {
+	isBaseResponse_Data()
+}
+
+type BaseResponse_Created struct {
+	Created *CreatedData `protobuf:"bytes,3,opt,name=created,proto3,oneof"`
+}
+
+type BaseResponse_Product struct {
+	Product *GetProductResponse `protobuf:"bytes,4,opt,name=product,proto3,oneof"`
+}
+
+type BaseResponse_Products struct {
+	Products *ProductPage `protobuf:"bytes,5,opt,name=products,proto3,oneof"`
+}
+
+type BaseResponse_History struct {
+	History *ListProductHistoryResponse `protobuf:"bytes,6,opt,name=history,proto3,oneof"`
+}
+
+type BaseResponse_Batch struct {
+	Batch *BatchResult `protobuf:"bytes,7,opt,name=batch,proto3,oneof"`
+}
+
+type BaseResponse_ImportResult struct {
+	ImportResult *ProductImportResult `protobuf:"bytes,8,opt,name=import_result,json=importResult,proto3,oneof"`
+}
+
+func (*BaseResponse_Created) isBaseResponse_Data() {}
+
+func (*BaseResponse_Product) isBaseResponse_Data() {}
+
+func (*BaseResponse_Products) isBaseResponse_Data() {}
+
+func (*BaseResponse_History) isBaseResponse_Data() {}
+
+func (*BaseResponse_Batch) isBaseResponse_Data() {}
+
+func (*BaseResponse_ImportResult) isBaseResponse_Data() {}
+
+type CreatedData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatedData) Reset() {
+	*x = CreatedData{}
+	mi := &file_product_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatedData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatedData) ProtoMessage() {}
+
+func (x *CreatedData) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatedData.ProtoReflect.Descriptor instead.
+func (*CreatedData) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreatedData) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ProductPage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*GetProductResponse  `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	Size          int32                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Total         int64                  `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductPage) Reset() {
+	*x = ProductPage{}
+	mi := &file_product_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductPage) ProtoMessage() {}
+
+func (x *ProductPage) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductPage.ProtoReflect.Descriptor instead.
+func (*ProductPage) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ProductPage) GetItems() []*GetProductResponse {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ProductPage) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ProductPage) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ProductPage) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type ProductCreation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductCreation) Reset() {
+	*x = ProductCreation{}
+	mi := &file_product_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductCreation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductCreation) ProtoMessage() {}
+
+func (x *ProductCreation) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductCreation.ProtoReflect.Descriptor instead.
+func (*ProductCreation) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ProductCreation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ProductUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductUpdate) Reset() {
+	*x = ProductUpdate{}
+	mi := &file_product_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductUpdate) ProtoMessage() {}
+
+func (x *ProductUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductUpdate.ProtoReflect.Descriptor instead.
+func (*ProductUpdate) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ProductUpdate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ProductUpsert struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sku           string                 `protobuf:"bytes,1,opt,name=sku,proto3" json:"sku,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductUpsert) Reset() {
+	*x = ProductUpsert{}
+	mi := &file_product_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductUpsert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductUpsert) ProtoMessage() {}
+
+func (x *ProductUpsert) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductUpsert.ProtoReflect.Descriptor instead.
+func (*ProductUpsert) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ProductUpsert) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *ProductUpsert) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type BatchCreateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*ProductCreation     `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCreateRequest) Reset() {
+	*x = BatchCreateRequest{}
+	mi := &file_product_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCreateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateRequest) ProtoMessage() {}
+
+func (x *BatchCreateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateRequest.ProtoReflect.Descriptor instead.
+func (*BatchCreateRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *BatchCreateRequest) GetItems() []*ProductCreation {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BatchUpsertRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*ProductUpsert       `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchUpsertRequest) Reset() {
+	*x = BatchUpsertRequest{}
+	mi := &file_product_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchUpsertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchUpsertRequest) ProtoMessage() {}
+
+func (x *BatchUpsertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchUpsertRequest.ProtoReflect.Descriptor instead.
+func (*BatchUpsertRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *BatchUpsertRequest) GetItems() []*ProductUpsert {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BatchDeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []uint64               `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchDeleteRequest) Reset() {
+	*x = BatchDeleteRequest{}
+	mi := &file_product_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchDeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDeleteRequest) ProtoMessage() {}
+
+func (x *BatchDeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDeleteRequest.ProtoReflect.Descriptor instead.
+func (*BatchDeleteRequest) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *BatchDeleteRequest) GetIds() []uint64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type BatchItemResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Id            uint64                 `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorCode     string                 `protobuf:"bytes,4,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchItemResult) Reset() {
+	*x = BatchItemResult{}
+	mi := &file_product_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchItemResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchItemResult) ProtoMessage() {}
+
+func (x *BatchItemResult) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchItemResult.ProtoReflect.Descriptor instead.
+func (*BatchItemResult) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *BatchItemResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchItemResult) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *BatchItemResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *BatchItemResult) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *BatchItemResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BatchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Succeeded     int32                  `protobuf:"varint,1,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Items         []*BatchItemResult     `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResult) Reset() {
+	*x = BatchResult{}
+	mi := &file_product_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResult) ProtoMessage() {}
+
+func (x *BatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResult.ProtoReflect.Descriptor instead.
+func (*BatchResult) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *BatchResult) GetSucceeded() int32 {
+	if x != nil {
+		return x.Succeeded
+	}
+	return 0
+}
+
+func (x *BatchResult) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *BatchResult) GetItems() []*BatchItemResult {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ProductImportError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Row           int32                  `protobuf:"varint,1,opt,name=row,proto3" json:"row,omitempty"`
+	Sku           string                 `protobuf:"bytes,2,opt,name=sku,proto3" json:"sku,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	ErrorCode     string                 `protobuf:"bytes,4,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductImportError) Reset() {
+	*x = ProductImportError{}
+	mi := &file_product_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductImportError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductImportError) ProtoMessage() {}
+
+func (x *ProductImportError) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductImportError.ProtoReflect.Descriptor instead.
+func (*ProductImportError) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ProductImportError) GetRow() int32 {
+	if x != nil {
+		return x.Row
+	}
+	return 0
+}
+
+func (x *ProductImportError) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *ProductImportError) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProductImportError) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *ProductImportError) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ProductImportResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Succeeded     int32                  `protobuf:"varint,2,opt,name=succeeded,proto3" json:"succeeded,omitempty"`
+	Failed        int32                  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	ReportId      string                 `protobuf:"bytes,4,opt,name=report_id,json=reportId,proto3" json:"report_id,omitempty"`
+	Errors        []*ProductImportError  `protobuf:"bytes,5,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductImportResult) Reset() {
+	*x = ProductImportResult{}
+	mi := &file_product_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductImportResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductImportResult) ProtoMessage() {}
+
+func (x *ProductImportResult) ProtoReflect() protoreflect.Message {
+	mi := &file_product_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductImportResult.ProtoReflect.Descriptor instead.
+func (*ProductImportResult) Descriptor() ([]byte, []int) {
+	return file_product_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ProductImportResult) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ProductImportResult) GetSucceeded() int32 {
+	if x != nil {
+		return x.Succeeded
+	}
+	return 0
+}
+
+func (x *ProductImportResult) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ProductImportResult) GetReportId() string {
+	if x != nil {
+		return x.ReportId
+	}
+	return ""
+}
+
+func (x *ProductImportResult) GetErrors() []*ProductImportError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+var File_product_proto protoreflect.FileDescriptor
+
+const file_product_proto_rawDesc = "" +
+	"\n" +
+	"\rproduct.proto\x12\rproto.product\x1a\x1fgoogle/protobuf/timestamp.proto\"T\n" +
+	"\x11GetProductRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12/\n" +
+	"\x05as_of\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"J\n" +
+	"\x12GetProductResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x10\n" +
+	"\x03sku\x18\x03 \x01(\tR\x03sku\"S\n" +
+	"\x19ListProductHistoryRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x05R\x04size\"\xfc\x01\n" +
+	"\x13ProductHistoryEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x04R\tproductId\x12\x1c\n" +
+	"\toperation\x18\x03 \x01(\tR\toperation\x12\x16\n" +
+	"\x06before\x18\x04 \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\x05 \x01(\tR\x05after\x12\x14\n" +
+	"\x05actor\x18\x06 \x01(\tR\x05actor\x12\x19\n" +
+	"\btrace_id\x18\a \x01(\tR\atraceId\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x94\x01\n" +
+	"\x1aListProductHistoryResponse\x128\n" +
+	"\x05items\x18\x01 \x03(\v2\".proto.product.ProductHistoryEntryR\x05items\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x05R\x04size\x12\x14\n" +
+	"\x05total\x18\x04 \x01(\x03R\x05total\"\xdd\x03\n" +
+	"\fBaseResponse\x12#\n" +
+	"\rresponse_code\x18\x01 \x01(\tR\fresponseCode\x12)\n" +
+	"\x10response_message\x18\x02 \x01(\tR\x0fresponseMessage\x126\n" +
+	"\acreated\x18\x03 \x01(\v2\x1a.proto.product.CreatedDataH\x00R\acreated\x12=\n" +
+	"\aproduct\x18\x04 \x01(\v2!.proto.product.GetProductResponseH\x00R\aproduct\x128\n" +
+	"\bproducts\x18\x05 \x01(\v2\x1a.proto.product.ProductPageH\x00R\bproducts\x12E\n" +
+	"\ahistory\x18\x06 \x01(\v2).proto.product.ListProductHistoryResponseH\x00R\ahistory\x122\n" +
+	"\x05batch\x18\a \x01(\v2\x1a.proto.product.BatchResultH\x00R\x05batch\x12I\n" +
+	"\rimport_result\x18\b \x01(\v2\".proto.product.ProductImportResultH\x00R\fimportResultB\x06\n" +
+	"\x04data\"\x1d\n" +
+	"\vCreatedData\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x84\x01\n" +
+	"\vProductPage\x127\n" +
+	"\x05items\x18\x01 \x03(\v2!.proto.product.GetProductResponseR\x05items\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x05R\x04size\x12\x14\n" +
+	"\x05total\x18\x04 \x01(\x03R\x05total\"%\n" +
+	"\x0fProductCreation\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"#\n" +
+	"\rProductUpdate\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"5\n" +
+	"\rProductUpsert\x12\x10\n" +
+	"\x03sku\x18\x01 \x01(\tR\x03sku\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"J\n" +
+	"\x12BatchCreateRequest\x124\n" +
+	"\x05items\x18\x01 \x03(\v2\x1e.proto.product.ProductCreationR\x05items\"H\n" +
+	"\x12BatchUpsertRequest\x122\n" +
+	"\x05items\x18\x01 \x03(\v2\x1c.proto.product.ProductUpsertR\x05items\"&\n" +
+	"\x12BatchDeleteRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x04R\x03ids\"\x86\x01\n" +
+	"\x0fBatchItemResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x04R\x02id\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x04 \x01(\tR\terrorCode\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"y\n" +
+	"\vBatchResult\x12\x1c\n" +
+	"\tsucceeded\x18\x01 \x01(\x05R\tsucceeded\x12\x16\n" +
+	"\x06failed\x18\x02 \x01(\x05R\x06failed\x124\n" +
+	"\x05items\x18\x03 \x03(\v2\x1e.proto.product.BatchItemResultR\x05items\"\x81\x01\n" +
+	"\x12ProductImportError\x12\x10\n" +
+	"\x03row\x18\x01 \x01(\x05R\x03row\x12\x10\n" +
+	"\x03sku\x18\x02 \x01(\tR\x03sku\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x04 \x01(\tR\terrorCode\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"\xb9\x01\n" +
+	"\x13ProductImportResult\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x12\x1c\n" +
+	"\tsucceeded\x18\x02 \x01(\x05R\tsucceeded\x12\x16\n" +
+	"\x06failed\x18\x03 \x01(\x05R\x06failed\x12\x1b\n" +
+	"\treport_id\x18\x04 \x01(\tR\breportId\x129\n" +
+	"\x06errors\x18\x05 \x03(\v2!.proto.product.ProductImportErrorR\x06errors2\xd2\x01\n" +
+	"\x0eProductService\x12S\n" +
+	"\n" +
+	"GetProduct\x12 .proto.product.GetProductRequest\x1a!.proto.product.GetProductResponse\"\x00\x12k\n" +
+	"\x12ListProductHistory\x12(.proto.product.ListProductHistoryRequest\x1a).proto.product.ListProductHistoryResponse\"\x00B\x0eZ\f./pb/productb\x06proto3"
+
+var (
+	file_product_proto_rawDescOnce sync.Once
+	file_product_proto_rawDescData []byte
+)
+
+func file_product_proto_rawDescGZIP() []byte {
+	file_product_proto_rawDescOnce.Do(func() {
+		file_product_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_product_proto_rawDesc), len(file_product_proto_rawDesc)))
+	})
+	return file_product_proto_rawDescData
+}
+
+var file_product_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_product_proto_goTypes = []any{
+	(*GetProductRequest)(nil),          // 0: proto.product.GetProductRequest
+	(*GetProductResponse)(nil),         // 1: proto.product.GetProductResponse
+	(*ListProductHistoryRequest)(nil),  // 2: proto.product.ListProductHistoryRequest
+	(*ProductHistoryEntry)(nil),        // 3: proto.product.ProductHistoryEntry
+	(*ListProductHistoryResponse)(nil), // 4: proto.product.ListProductHistoryResponse
+	(*BaseResponse)(nil),               // 5: proto.product.BaseResponse
+	(*CreatedData)(nil),                // 6: proto.product.CreatedData
+	(*ProductPage)(nil),                // 7: proto.product.ProductPage
+	(*ProductCreation)(nil),            // 8: proto.product.ProductCreation
+	(*ProductUpdate)(nil),              // 9: proto.product.ProductUpdate
+	(*ProductUpsert)(nil),              // 10: proto.product.ProductUpsert
+	(*BatchCreateRequest)(nil),         // 11: proto.product.BatchCreateRequest
+	(*BatchUpsertRequest)(nil),         // 12: proto.product.BatchUpsertRequest
+	(*BatchDeleteRequest)(nil),         // 13: proto.product.BatchDeleteRequest
+	(*BatchItemResult)(nil),            // 14: proto.product.BatchItemResult
+	(*BatchResult)(nil),                // 15: proto.product.BatchResult
+	(*ProductImportError)(nil),         // 16: proto.product.ProductImportError
+	(*ProductImportResult)(nil),        // 17: proto.product.ProductImportResult
+	(*timestamppb.Timestamp)(nil),      // 18: google.protobuf.Timestamp
+}
+var file_product_proto_depIdxs = []int32{
+	18, // 0: proto.product.GetProductRequest.as_of:type_name -> google.protobuf.Timestamp
+	18, // 1: proto.product.ProductHistoryEntry.created_at:type_name -> google.protobuf.Timestamp
+	3,  // 2: proto.product.ListProductHistoryResponse.items:type_name -> proto.product.ProductHistoryEntry
+	6,  // 3: proto.product.BaseResponse.created:type_name -> proto.product.CreatedData
+	1,  // 4: proto.product.BaseResponse.product:type_name -> proto.product.GetProductResponse
+	7,  // 5: proto.product.BaseResponse.products:type_name -> proto.product.ProductPage
+	4,  // 6: proto.product.BaseResponse.history:type_name -> proto.product.ListProductHistoryResponse
+	15, // 7: proto.product.BaseResponse.batch:type_name -> proto.product.BatchResult
+	17, // 8: proto.product.BaseResponse.import_result:type_name -> proto.product.ProductImportResult
+	1,  // 9: proto.product.ProductPage.items:type_name -> proto.product.GetProductResponse
+	8,  // 10: proto.product.BatchCreateRequest.items:type_name -> proto.product.ProductCreation
+	10, // 11: proto.product.BatchUpsertRequest.items:type_name -> proto.product.ProductUpsert
+	14, // 12: proto.product.BatchResult.items:type_name -> proto.product.BatchItemResult
+	16, // 13: proto.product.ProductImportResult.errors:type_name -> proto.product.ProductImportError
+	0,  // 14: proto.product.ProductService.GetProduct:input_type -> proto.product.GetProductRequest
+	2,  // 15: proto.product.ProductService.ListProductHistory:input_type -> proto.product.ListProductHistoryRequest
+	1,  // 16: proto.product.ProductService.GetProduct:output_type -> proto.product.GetProductResponse
+	4,  // 17: proto.product.ProductService.ListProductHistory:output_type -> proto.product.ListProductHistoryResponse
+	16, // [16:18] is the sub-list for method output_type
+	14, // [14:16] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_product_proto_init() }
+func file_product_proto_init() {
+	if File_product_proto != nil {
+		return
+	}
+	file_product_proto_msgTypes[5].OneofWrappers = []any{
+		(*BaseResponse_Created)(nil),
+		(*BaseResponse_Product)(nil),
+		(*BaseResponse_Products)(nil),
+		(*BaseResponse_History)(nil),
+		(*BaseResponse_Batch)(nil),
+		(*BaseResponse_ImportResult)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -169,7 +1368,7 @@ func file_product_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_product_proto_rawDesc), len(file_product_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   1,
 		},